@@ -7,6 +7,7 @@ import (
 
 	"securityrota-api/models"
 
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
@@ -14,11 +15,11 @@ import (
 var DB *gorm.DB
 
 func Connect() {
-	host := getEnv("DB_HOST", "localhost")
-	port := getEnv("DB_PORT", "5432")
-	user := getEnv("DB_USER", "rota")
-	password := getEnv("DB_PASSWORD", "rotapass")
-	dbname := getEnv("DB_NAME", "securityrota")
+	host := GetEnv("DB_HOST", "localhost")
+	port := GetEnv("DB_PORT", "5432")
+	user := GetEnv("DB_USER", "rota")
+	password := GetEnv("DB_PASSWORD", "rotapass")
+	dbname := GetEnv("DB_NAME", "securityrota")
 
 	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		host, port, user, password, dbname)
@@ -31,16 +32,207 @@ func Connect() {
 
 	log.Println("Database connected successfully")
 
+	removeDuplicateShifts()
+
+	// Names and badge numbers were unique across deleted officers too, so they could never be reused
+	for _, index := range []string{"idx_officers_name", "idx_officers_badge_no"} {
+		if DB.Migrator().HasIndex(&models.Officer{}, index) {
+			DB.Migrator().DropIndex(&models.Officer{}, index)
+		}
+	}
+
+	// Day shifts always needed a supervisor before coverage rules could say so
+	addSergeantRule := !DB.Migrator().HasColumn(&models.CoverageRule{}, "RequireSergeant")
+
 	// Auto migrate models
-	err = DB.AutoMigrate(&models.Officer{}, &models.Shift{}, &models.WeekRotation{})
+	err = DB.AutoMigrate(&models.Officer{}, &models.Qualification{}, &models.Shift{}, &models.WeekRotation{}, &models.User{}, &models.Leave{}, &models.ShiftSwap{},
+		&models.RuleSet{}, &models.ShiftTemplate{}, &models.StaffingRule{}, &models.CoverageRule{},
+		&models.RotationCycle{}, &models.RotationCycleStep{}, &models.GenerationRun{}, &models.Holiday{}, &models.ShiftDefinition{}, &models.CalendarToken{})
 	if err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
+
+	// Accounts created before role-based access used the generic "User" role
+	DB.Model(&models.User{}).Where("role = ?", "User").Update("role", models.UserRoleViewer)
+
+	// Rotations from before rotation cycles only stored the day team of two
+	DB.Model(&models.WeekRotation{}).
+		Where("night_shift_team = 0 AND day_shift_team IN (1, 2)").
+		Update("night_shift_team", gorm.Expr("3 - day_shift_team"))
+
+	migrateFemaleRole()
+	removeFemaleTemplates()
+	if addSergeantRule {
+		migrateSergeantCoverage()
+	}
+
+	seedDefaultUsers()
+	seedDefaultShiftDefinitions()
+	seedDefaultRuleSet()
+	seedDefaultRotationCycle()
+}
+
+// migrateSergeantCoverage makes every existing rule set require a supervisor (a sergeant or acting sergeant)
+// on day shifts, the check swap approval made before coverage rules were configurable, as the default
+// rule set does
+func migrateSergeantCoverage() {
+	var ruleSets []models.RuleSet
+	DB.Preload("Coverage").Find(&ruleSets)
+	for _, ruleSet := range ruleSets {
+		found := false
+		for _, rule := range ruleSet.Coverage {
+			if rule.ShiftType == models.ShiftDay && rule.Weekday == nil {
+				DB.Model(&rule).Update("require_supervisor", true)
+				found = true
+			}
+		}
+		if !found {
+			DB.Create(&models.CoverageRule{RuleSetID: ruleSet.ID, ShiftType: models.ShiftDay, RequireSupervisor: true})
+		}
+	}
+}
+
+// removeDuplicateShifts keeps the oldest of any shifts sharing officer, date and shift type
+// so the unique index on those columns can be created
+func removeDuplicateShifts() {
+	if !DB.Migrator().HasTable(&models.Shift{}) {
+		return
+	}
+
+	duplicates := DB.Table("shifts AS s").Select("s.id").
+		Joins("JOIN shifts AS k ON k.officer_id = s.officer_id AND k.date = s.date AND k.shift_type = s.shift_type AND k.id < s.id")
+
+	if DB.Migrator().HasTable(&models.ShiftSwap{}) {
+		DB.Where("requester_shift_id IN (?) OR target_shift_id IN (?)", duplicates, duplicates).Delete(&models.ShiftSwap{})
+	}
+	result := DB.Where("id IN (?)", duplicates).Delete(&models.Shift{})
+	if result.RowsAffected > 0 {
+		log.Printf("Removed %d duplicate shifts", result.RowsAffected)
+	}
+}
+
+// migrateFemaleRole converts the old "female" officer role into the gender attribute. Those officers
+// become regulars, templates that selected them select regular female officers instead, and their
+// rule sets gain the female day-shift coverage rule the templates used to guarantee.
+func migrateFemaleRole() {
+	const legacyRole = "female"
+
+	DB.Unscoped().Model(&models.Officer{}).Where("role = ?", legacyRole).
+		Updates(map[string]interface{}{"role": models.RoleRegular, "gender": models.GenderFemale})
+
+	var templates []models.ShiftTemplate
+	DB.Where("role = ?", legacyRole).Find(&templates)
+	migrated := make(map[uint]bool)
+	for _, t := range templates {
+		if migrated[t.RuleSetID] {
+			continue
+		}
+		migrated[t.RuleSetID] = true
+
+		var count int64
+		DB.Model(&models.CoverageRule{}).Where("rule_set_id = ?", t.RuleSetID).Count(&count)
+		if count == 0 {
+			DB.Create(&models.CoverageRule{RuleSetID: t.RuleSetID, ShiftType: models.ShiftDay, RequireSupervisor: true, MinFemale: 1})
+		}
+	}
+	DB.Model(&models.ShiftTemplate{}).Where("role = ?", legacyRole).
+		Updates(map[string]interface{}{"role": models.RoleRegular, "gender": models.GenderFemale})
+}
+
+// removeFemaleTemplates deletes the two positional female day-shift templates the default rule set used to
+// seed, so those officers rotate with their teams; the female coverage rule keeps a woman on every day shift
+func removeFemaleTemplates() {
+	seeded := DB.Model(&models.RuleSet{}).Select("id").Where("name = ?", "Default")
+	result := DB.Where("rule_set_id IN (?) AND name IN ? AND role = ? AND gender = ? AND position IS NOT NULL AND officer_id IS NULL",
+		seeded, []string{"Female officer 1", "Female officer 2"}, models.RoleRegular, models.GenderFemale).
+		Delete(&models.ShiftTemplate{})
+	if result.RowsAffected > 0 {
+		log.Printf("Removed %d female officer templates from the default rule set", result.RowsAffected)
+	}
+}
+
+// seedDefaultUsers creates the default accounts on an empty users table
+func seedDefaultUsers() {
+	var count int64
+	DB.Model(&models.User{}).Count(&count)
+	if count > 0 {
+		return
+	}
+
+	defaults := []struct {
+		user     models.User
+		password string
+	}{
+		{models.User{Username: "admin", FullName: "Administrator", Email: "admin@security.local", Role: models.UserRoleAdmin}, GetEnv("ADMIN_PASSWORD", "admin123")},
+		{models.User{Username: "user", FullName: "Regular User", Email: "user@security.local", Role: models.UserRoleViewer}, "user123"},
+	}
+
+	for _, d := range defaults {
+		hash, err := bcrypt.GenerateFromPassword([]byte(d.password), bcrypt.DefaultCost)
+		if err != nil {
+			log.Fatal("Failed to hash default password:", err)
+		}
+		d.user.PasswordHash = string(hash)
+		if err := DB.Create(&d.user).Error; err != nil {
+			log.Fatal("Failed to seed default users:", err)
+		}
+	}
+
+	log.Println("Seeded default users")
 }
 
-func getEnv(key, fallback string) string {
+// GetEnv returns an environment variable, or fallback when it is not set
+func GetEnv(key, fallback string) string {
 	if value, ok := os.LookupEnv(key); ok {
 		return value
 	}
 	return fallback
 }
+
+// seedDefaultShiftDefinitions installs the day and night shifts when no shift is defined
+func seedDefaultShiftDefinitions() {
+	var count int64
+	DB.Model(&models.ShiftDefinition{}).Count(&count)
+	if count > 0 {
+		return
+	}
+
+	definitions := models.DefaultShiftDefinitions()
+	if err := DB.Create(&definitions).Error; err != nil {
+		log.Fatal("Failed to seed default shift definitions:", err)
+	}
+
+	log.Println("Seeded default shift definitions")
+}
+
+// seedDefaultRuleSet installs the built-in rotation rules when no rule set exists
+func seedDefaultRuleSet() {
+	var count int64
+	DB.Model(&models.RuleSet{}).Count(&count)
+	if count > 0 {
+		return
+	}
+
+	rules := models.DefaultRuleSet()
+	if err := DB.Create(&rules).Error; err != nil {
+		log.Fatal("Failed to seed default rule set:", err)
+	}
+
+	log.Println("Seeded default rotation rules")
+}
+
+// seedDefaultRotationCycle installs the two-team weekly alternation when no cycle exists
+func seedDefaultRotationCycle() {
+	var count int64
+	DB.Model(&models.RotationCycle{}).Count(&count)
+	if count > 0 {
+		return
+	}
+
+	cycle := models.DefaultRotationCycle()
+	if err := DB.Create(&cycle).Error; err != nil {
+		log.Fatal("Failed to seed default rotation cycle:", err)
+	}
+
+	log.Println("Seeded default rotation cycle")
+}
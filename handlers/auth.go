@@ -6,8 +6,12 @@ import (
 	"strings"
 	"time"
 
+	"securityrota-api/database"
+	"securityrota-api/models"
+
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
 )
 
 var jwtSecret = []byte(getJWTSecret())
@@ -20,22 +24,6 @@ func getJWTSecret() string {
 	return secret
 }
 
-// User represents a simple user (in production, use database)
-type User struct {
-	ID       uint   `json:"id"`
-	Username string `json:"username"`
-	Password string `json:"-"`
-	FullName string `json:"fullName"`
-	Email    string `json:"email"`
-	Role     string `json:"role"`
-}
-
-// Simple in-memory users (replace with database in production)
-var users = []User{
-	{ID: 1, Username: "admin", Password: "admin123", FullName: "Administrator", Email: "admin@security.local", Role: "Admin"},
-	{ID: 2, Username: "user", Password: "user123", FullName: "Regular User", Email: "user@security.local", Role: "User"},
-}
-
 // LoginInput represents login credentials
 type LoginInput struct {
 	Username string `json:"username" binding:"required"`
@@ -44,8 +32,8 @@ type LoginInput struct {
 
 // LoginResponse represents the login response
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token string      `json:"token"`
+	User  models.User `json:"user"`
 }
 
 // Claims represents JWT claims
@@ -73,34 +61,19 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// Find user
-	var foundUser *User
-	for _, u := range users {
-		if u.Username == input.Username && u.Password == input.Password {
-			foundUser = &u
-			break
-		}
-	}
-
-	if foundUser == nil {
+	// Find user and verify password
+	var user models.User
+	if err := database.DB.Where("username = ?", input.Username).First(&user).Error; err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
 		return
 	}
 
-	// Generate JWT token
-	expirationTime := time.Now().Add(24 * time.Hour)
-	claims := &Claims{
-		UserID:   foundUser.ID,
-		Username: foundUser.Username,
-		Role:     foundUser.Role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-		},
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(input.Password)) != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtSecret)
+	tokenString, err := generateToken(user)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
@@ -108,7 +81,7 @@ func Login(c *gin.Context) {
 
 	c.JSON(http.StatusOK, LoginResponse{
 		Token: tokenString,
-		User:  *foundUser,
+		User:  user,
 	})
 }
 
@@ -118,7 +91,7 @@ func Login(c *gin.Context) {
 // @Tags auth
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} User
+// @Success 200 {object} models.User
 // @Failure 401 {object} map[string]string
 // @Router /auth/profile [get]
 func GetProfile(c *gin.Context) {
@@ -128,14 +101,13 @@ func GetProfile(c *gin.Context) {
 		return
 	}
 
-	for _, u := range users {
-		if u.ID == userID.(uint) {
-			c.JSON(http.StatusOK, u)
-			return
-		}
+	var user models.User
+	if err := database.DB.First(&user, userID.(uint)).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
 	}
 
-	c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	c.JSON(http.StatusOK, user)
 }
 
 // RefreshToken godoc
@@ -154,25 +126,32 @@ func RefreshToken(c *gin.Context) {
 		return
 	}
 
-	var foundUser *User
-	for _, u := range users {
-		if u.ID == userID.(uint) {
-			foundUser = &u
-			break
-		}
-	}
-
-	if foundUser == nil {
+	var user models.User
+	if err := database.DB.First(&user, userID.(uint)).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
 	}
 
 	// Generate new token
+	tokenString, err := generateToken(user)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, LoginResponse{
+		Token: tokenString,
+		User:  user,
+	})
+}
+
+// generateToken issues a signed JWT for the given user
+func generateToken(user models.User) (string, error) {
 	expirationTime := time.Now().Add(24 * time.Hour)
 	claims := &Claims{
-		UserID:   foundUser.ID,
-		Username: foundUser.Username,
-		Role:     foundUser.Role,
+		UserID:   user.ID,
+		Username: user.Username,
+		Role:     user.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -180,16 +159,7 @@ func RefreshToken(c *gin.Context) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(jwtSecret)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
-		return
-	}
-
-	c.JSON(http.StatusOK, LoginResponse{
-		Token: tokenString,
-		User:  *foundUser,
-	})
+	return token.SignedString(jwtSecret)
 }
 
 // AuthMiddleware validates JWT tokens
@@ -220,15 +190,57 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// The account may have been deleted or its role changed since the token was issued
+		var user models.User
+		if err := database.DB.First(&user, claims.UserID).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User no longer exists"})
+			c.Abort()
+			return
+		}
+
 		// Set user info in context
-		c.Set("userID", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
+		c.Set("userID", user.ID)
+		c.Set("username", user.Username)
+		c.Set("role", user.Role)
 
 		c.Next()
 	}
 }
 
+// RequireRole allows the request through only if the authenticated user has one of the given roles.
+// It must be used after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role := c.GetString("role")
+		for _, r := range roles {
+			if r == role {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":          "You do not have permission to perform this action",
+			"role":           role,
+			"required_roles": roles,
+		})
+		c.Abort()
+	}
+}
+
+// currentUser loads the authenticated user's account
+func currentUser(c *gin.Context) (models.User, bool) {
+	var user models.User
+	userID, exists := c.Get("userID")
+	if !exists {
+		return user, false
+	}
+	if err := database.DB.First(&user, userID.(uint)).Error; err != nil {
+		return user, false
+	}
+	return user, true
+}
+
 // OptionalAuthMiddleware allows unauthenticated requests but sets user if token present
 func OptionalAuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -249,10 +261,11 @@ func OptionalAuthMiddleware() gin.HandlerFunc {
 			return jwtSecret, nil
 		})
 
-		if err == nil && token.Valid {
-			c.Set("userID", claims.UserID)
-			c.Set("username", claims.Username)
-			c.Set("role", claims.Role)
+		var user models.User
+		if err == nil && token.Valid && database.DB.First(&user, claims.UserID).Error == nil {
+			c.Set("userID", user.ID)
+			c.Set("username", user.Username)
+			c.Set("role", user.Role)
 		}
 
 		c.Next()
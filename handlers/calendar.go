@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// calendarLookbackDays is how far back calendar feeds include past shifts
+const calendarLookbackDays = 56
+
+// CalendarTokenResponse represents an officer's calendar token and the feed URLs that use it
+type CalendarTokenResponse struct {
+	OfficerID  uint      `json:"officer_id"`
+	Token      string    `json:"token"`
+	OfficerURL string    `json:"officer_url"` // Path of the officer's own feed
+	TeamURL    string    `json:"team_url"`    // Path of the officer's team feed
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// GetCalendarToken godoc
+// @Summary Get an officer's calendar feed token
+// @Description Get the token and URLs of an officer's calendar feeds. Officers may only see their own.
+// @Tags calendar
+// @Produce json
+// @Param id path int true "Officer ID"
+// @Success 200 {object} CalendarTokenResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /officers/{id}/calendar-token [get]
+func GetCalendarToken(c *gin.Context) {
+	officer, ok := calendarOfficer(c)
+	if !ok {
+		return
+	}
+
+	var token models.CalendarToken
+	if err := database.DB.Where("officer_id = ?", officer.ID).First(&token).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Officer has no calendar token"})
+		return
+	}
+	c.JSON(http.StatusOK, calendarTokenResponse(officer, token))
+}
+
+// CreateCalendarToken godoc
+// @Summary Issue an officer's calendar feed token
+// @Description Issue a new calendar feed token for an officer. Any previous token stops working.
+// @Tags calendar
+// @Produce json
+// @Param id path int true "Officer ID"
+// @Success 201 {object} CalendarTokenResponse
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /officers/{id}/calendar-token [post]
+func CreateCalendarToken(c *gin.Context) {
+	officer, ok := calendarOfficer(c)
+	if !ok {
+		return
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
+		return
+	}
+
+	token := models.CalendarToken{OfficerID: officer.ID, Token: hex.EncodeToString(secret)}
+	database.DB.Where("officer_id = ?", officer.ID).Delete(&models.CalendarToken{})
+	if err := database.DB.Create(&token).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save token"})
+		return
+	}
+	c.JSON(http.StatusCreated, calendarTokenResponse(officer, token))
+}
+
+// DeleteCalendarToken godoc
+// @Summary Revoke an officer's calendar feed token
+// @Description Revoke an officer's calendar feed token; calendars subscribed with it stop updating
+// @Tags calendar
+// @Param id path int true "Officer ID"
+// @Success 204
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /officers/{id}/calendar-token [delete]
+func DeleteCalendarToken(c *gin.Context) {
+	officer, ok := calendarOfficer(c)
+	if !ok {
+		return
+	}
+
+	database.DB.Where("officer_id = ?", officer.ID).Delete(&models.CalendarToken{})
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetOfficerCalendar godoc
+// @Summary Officer calendar feed
+// @Description iCalendar feed of an officer's on-duty shifts, from 8 weeks ago onwards
+// @Tags calendar
+// @Produce text/calendar
+// @Param file path string true "Officer ID followed by .ics"
+// @Param token query string true "The officer's calendar token"
+// @Success 200 {file} file
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /calendar/officer/{file} [get]
+func GetOfficerCalendar(c *gin.Context) {
+	id, err := strconv.Atoi(strings.TrimSuffix(c.Param("file"), ".ics"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+
+	owner, ok := calendarTokenOwner(c)
+	if !ok {
+		return
+	}
+	if owner.ID != uint(id) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked calendar token"})
+		return
+	}
+
+	definitions, err := loadShiftDefinitions(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shift definitions"})
+		return
+	}
+
+	var shifts []models.Shift
+	if err := database.DB.Where("officer_id = ? AND status = ? AND date >= ?", owner.ID, models.StatusOnDuty, calendarFrom()).
+		Order("date ASC").Find(&shifts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shifts"})
+		return
+	}
+
+	cal := newICSCalendar(owner.Name + " - Duty Rota")
+	for _, shift := range shifts {
+		definition := findShiftDefinition(definitions, shift.ShiftType)
+		if definition == nil {
+			continue
+		}
+		summary := definition.Label
+		if shift.Relief {
+			summary += " (relief supervisor)"
+		}
+		cal.addEvent(
+			fmt.Sprintf("%s-officer-%d@securityrota", shift.Date.Format("20060102"), owner.ID),
+			*definition, shift.Date, shift.UpdatedAt, summary, "")
+	}
+	writeICS(c, fmt.Sprintf("officer_%d.ics", owner.ID), cal)
+}
+
+// GetTeamCalendar godoc
+// @Summary Team calendar feed
+// @Description iCalendar feed of the shifts a team's officers are on duty, one event per shift listing who is
+// @Description working, from 8 weeks ago onwards. Any officer of the team can use their token.
+// @Tags calendar
+// @Produce text/calendar
+// @Param file path string true "Team number followed by .ics"
+// @Param token query string true "A team officer's calendar token"
+// @Success 200 {file} file
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /calendar/team/{file} [get]
+func GetTeamCalendar(c *gin.Context) {
+	team, err := strconv.Atoi(strings.TrimSuffix(c.Param("file"), ".ics"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Calendar not found"})
+		return
+	}
+
+	owner, ok := calendarTokenOwner(c)
+	if !ok {
+		return
+	}
+	if owner.Team != team {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked calendar token"})
+		return
+	}
+
+	definitions, err := loadShiftDefinitions(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shift definitions"})
+		return
+	}
+
+	var shifts []models.Shift
+	if err := database.DB.Preload("Officer", withFormerOfficers).
+		Joins("JOIN officers ON officers.id = shifts.officer_id AND officers.team = ?", team).
+		Where("shifts.status = ? AND shifts.date >= ?", models.StatusOnDuty, calendarFrom()).
+		Order("shifts.date ASC, officers.name ASC").Find(&shifts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shifts"})
+		return
+	}
+
+	// One event per date and shift, naming the officers on it
+	type teamShift struct {
+		date      time.Time
+		shiftType models.ShiftType
+		names     []string
+		updated   time.Time
+	}
+	var order []string
+	events := make(map[string]*teamShift)
+	for _, shift := range shifts {
+		key := shift.Date.Format("20060102") + "-" + string(shift.ShiftType)
+		event, ok := events[key]
+		if !ok {
+			event = &teamShift{date: shift.Date, shiftType: shift.ShiftType}
+			events[key] = event
+			order = append(order, key)
+		}
+		name := shift.Officer.Name
+		if shift.Relief {
+			name += " (relief supervisor)"
+		}
+		event.names = append(event.names, name)
+		if shift.UpdatedAt.After(event.updated) {
+			event.updated = shift.UpdatedAt
+		}
+	}
+
+	cal := newICSCalendar(fmt.Sprintf("Team %d - Duty Rota", team))
+	for _, key := range order {
+		event := events[key]
+		definition := findShiftDefinition(definitions, event.shiftType)
+		if definition == nil {
+			continue
+		}
+		cal.addEvent(
+			fmt.Sprintf("%s-team-%d@securityrota", key, team),
+			*definition, event.date, event.updated,
+			fmt.Sprintf("Team %d %s", team, definition.Label), strings.Join(event.names, "\n"))
+	}
+	writeICS(c, fmt.Sprintf("team_%d.ics", team), cal)
+}
+
+// calendarOfficer loads the officer named in the URL, checking the user may manage their calendar token
+func calendarOfficer(c *gin.Context) (models.Officer, bool) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var officer models.Officer
+	if err := database.DB.First(&officer, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Officer not found"})
+		return officer, false
+	}
+	if !actsForOfficer(c, officer.ID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only manage your own calendar token"})
+		return officer, false
+	}
+	return officer, true
+}
+
+// calendarTokenOwner returns the officer whose calendar token is in the request's token query parameter
+func calendarTokenOwner(c *gin.Context) (models.Officer, bool) {
+	var officer models.Officer
+	var token models.CalendarToken
+	if t := c.Query("token"); t == "" || database.DB.Where("token = ?", t).First(&token).Error != nil ||
+		database.DB.First(&officer, token.OfficerID).Error != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked calendar token"})
+		return officer, false
+	}
+	return officer, true
+}
+
+func calendarTokenResponse(officer models.Officer, token models.CalendarToken) CalendarTokenResponse {
+	return CalendarTokenResponse{
+		OfficerID:  officer.ID,
+		Token:      token.Token,
+		OfficerURL: fmt.Sprintf("/api/v1/calendar/officer/%d.ics?token=%s", officer.ID, token.Token),
+		TeamURL:    fmt.Sprintf("/api/v1/calendar/team/%d.ics?token=%s", officer.Team, token.Token),
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+// calendarFrom returns the first date calendar feeds include
+func calendarFrom() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -calendarLookbackDays)
+}
+
+// icsCalendar builds an RFC 5545 calendar. Event times are floating (site local time).
+type icsCalendar struct {
+	b strings.Builder
+}
+
+func newICSCalendar(name string) *icsCalendar {
+	cal := &icsCalendar{}
+	cal.line("BEGIN", "VCALENDAR")
+	cal.line("VERSION", "2.0")
+	cal.line("PRODID", "-//Security Rota//Duty Rota//EN")
+	cal.line("CALSCALE", "GREGORIAN")
+	cal.line("METHOD", "PUBLISH")
+	cal.line("X-WR-CALNAME", icsEscape(name))
+	return cal
+}
+
+// addEvent adds a shift worked on a date; the UID must stay the same when the shift is regenerated or edited
+func (cal *icsCalendar) addEvent(uid string, definition models.ShiftDefinition, date, updated time.Time, summary, description string) {
+	start, err := time.Parse("15:04", definition.StartTime)
+	if err != nil {
+		return
+	}
+	from := date.Add(time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute)
+	to := from.Add(time.Duration(definition.Hours() * float64(time.Hour)))
+
+	cal.line("BEGIN", "VEVENT")
+	cal.line("UID", uid)
+	cal.line("DTSTAMP", updated.UTC().Format("20060102T150405Z"))
+	cal.line("LAST-MODIFIED", updated.UTC().Format("20060102T150405Z"))
+	cal.line("DTSTART", from.Format("20060102T150405"))
+	cal.line("DTEND", to.Format("20060102T150405"))
+	cal.line("SUMMARY", icsEscape(summary))
+	if description != "" {
+		cal.line("DESCRIPTION", icsEscape(description))
+	}
+	cal.line("END", "VEVENT")
+}
+
+func (cal *icsCalendar) String() string {
+	return cal.b.String() + "END:VCALENDAR\r\n"
+}
+
+// line writes a content line, folded to 75 octets without splitting UTF-8 characters
+func (cal *icsCalendar) line(name, value string) {
+	content := name + ":" + value
+	limit := 75
+	for len(content) > limit {
+		cut := limit
+		for cut > 0 && content[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		cal.b.WriteString(content[:cut] + "\r\n ")
+		content = content[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	cal.b.WriteString(content + "\r\n")
+}
+
+// icsEscape escapes a TEXT value
+func icsEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+func writeICS(c *gin.Context, filename string, cal *icsCalendar) {
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%s", filename))
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(cal.String()))
+}
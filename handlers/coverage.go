@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CoverageShortfall is one coverage rule a shift does not meet
+type CoverageShortfall struct {
+	Date      string           `json:"date"`
+	ShiftType models.ShiftType `json:"shift_type"`
+	Rule      string           `json:"rule"` // min_on_duty, sergeant, supervisor or min_female
+	Required  int              `json:"required"`
+	Actual    int              `json:"actual"`
+	Message   string           `json:"message"`
+}
+
+// checkCoverage lists the coverage rules broken by the shifts on a date. Shifts must have their
+// Officer loaded; only on-duty shifts count.
+func checkCoverage(rules models.RuleSet, date time.Time, shifts []models.Shift) []CoverageShortfall {
+	onDuty := make(map[models.ShiftType]int)
+	sergeants := make(map[models.ShiftType]int)
+	supervisors := make(map[models.ShiftType]int)
+	females := make(map[models.ShiftType]int)
+	for _, shift := range shifts {
+		if !shift.Date.Equal(date) || shift.Status != models.StatusOnDuty {
+			continue
+		}
+		onDuty[shift.ShiftType]++
+		if shift.Officer.Role == models.RoleSergeant {
+			sergeants[shift.ShiftType]++
+		}
+		if shift.Officer.CanSupervise() {
+			supervisors[shift.ShiftType]++
+		}
+		if shift.Officer.Gender == models.GenderFemale {
+			females[shift.ShiftType]++
+		}
+	}
+
+	var shortfalls []CoverageShortfall
+	day := date.Format("2006-01-02")
+	add := func(shiftType models.ShiftType, rule string, required, actual int, message string) {
+		shortfalls = append(shortfalls, CoverageShortfall{
+			Date:      day,
+			ShiftType: shiftType,
+			Rule:      rule,
+			Required:  required,
+			Actual:    actual,
+			Message:   fmt.Sprintf("%s %s shift %s", day, shiftType, message),
+		})
+	}
+
+	for _, shiftType := range coveredShiftTypes(rules) {
+		rule := coverageRuleFor(rules, date.Weekday(), shiftType)
+		if rule == nil {
+			continue
+		}
+		if onDuty[shiftType] < rule.MinOnDuty {
+			add(shiftType, "min_on_duty", rule.MinOnDuty, onDuty[shiftType], fmt.Sprintf("has fewer than %d officers", rule.MinOnDuty))
+		}
+		if rule.RequireSergeant && sergeants[shiftType] == 0 {
+			add(shiftType, "sergeant", 1, 0, "has no sergeant")
+		}
+		if rule.RequireSupervisor && supervisors[shiftType] == 0 {
+			add(shiftType, "supervisor", 1, 0, "has no sergeant or acting sergeant")
+		}
+		if females[shiftType] < rule.MinFemale {
+			add(shiftType, "min_female", rule.MinFemale, females[shiftType], fmt.Sprintf("has fewer than %d female officers", rule.MinFemale))
+		}
+	}
+	return shortfalls
+}
+
+// coveredShiftTypes lists the shift types that have coverage rules, in rule order
+func coveredShiftTypes(rules models.RuleSet) []models.ShiftType {
+	var shiftTypes []models.ShiftType
+	seen := make(map[models.ShiftType]bool)
+	for _, rule := range rules.Coverage {
+		if !seen[rule.ShiftType] {
+			seen[rule.ShiftType] = true
+			shiftTypes = append(shiftTypes, rule.ShiftType)
+		}
+	}
+	return shiftTypes
+}
+
+// coverageShortfalls lists the messages for the coverage rules broken by the saved shifts on a date
+func coverageShortfalls(db *gorm.DB, date time.Time) []string {
+	var shifts []models.Shift
+	db.Preload("Officer", withFormerOfficers).
+		Where("date = ? AND status = ?", date, models.StatusOnDuty).
+		Find(&shifts)
+
+	rules, _ := loadActiveRuleSet(db)
+
+	var messages []string
+	for _, shortfall := range checkCoverage(rules, date, shifts) {
+		messages = append(messages, shortfall.Message)
+	}
+	return messages
+}
+
+// weekCoverage checks every day of a week against the coverage rules
+func weekCoverage(rules models.RuleSet, weekStart time.Time, shifts []models.Shift) []CoverageShortfall {
+	shortfalls := []CoverageShortfall{}
+	for i := 0; i < 7; i++ {
+		shortfalls = append(shortfalls, checkCoverage(rules, weekStart.AddDate(0, 0, i), shifts)...)
+	}
+	return shortfalls
+}
+
+// coverageRuleFor finds the coverage rule for a shift on a weekday; a rule for that weekday
+// takes precedence over an every-day rule
+func coverageRuleFor(rules models.RuleSet, weekday time.Weekday, shiftType models.ShiftType) *models.CoverageRule {
+	var everyDay *models.CoverageRule
+	for i := range rules.Coverage {
+		rule := &rules.Coverage[i]
+		if rule.ShiftType != shiftType {
+			continue
+		}
+		if rule.Weekday == nil {
+			everyDay = rule
+		} else if *rule.Weekday == int(weekday) {
+			return rule
+		}
+	}
+	return everyDay
+}
+
+// minFemale returns how many female officers a shift needs on a weekday
+func minFemale(rules models.RuleSet, weekday time.Weekday, shiftType models.ShiftType) int {
+	if rule := coverageRuleFor(rules, weekday, shiftType); rule != nil {
+		return rule.MinFemale
+	}
+	return 0
+}
+
+// newShortfalls returns the entries in after that were not already in before
+func newShortfalls(before, after []string) []string {
+	existing := make(map[string]bool, len(before))
+	for _, s := range before {
+		existing[s] = true
+	}
+
+	var added []string
+	for _, s := range after {
+		if !existing[s] {
+			added = append(added, s)
+		}
+	}
+	return added
+}
+
+// GetWeekCoverage godoc
+// @Summary Get week coverage report
+// @Description Check a week's rota against the active rule set's coverage rules and list every shortfall
+// @Tags rota
+// @Produce json
+// @Param week_start query string true "Week start date (YYYY-MM-DD, must be Sunday)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /rota/week/coverage [get]
+func GetWeekCoverage(c *gin.Context) {
+	weekStart, err := time.Parse("2006-01-02", c.Query("week_start"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+		return
+	}
+	if weekStart.Weekday() != time.Sunday {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Week start must be a Sunday"})
+		return
+	}
+
+	var rotation models.WeekRotation
+	if err := database.DB.Where("week_start = ?", weekStart).First(&rotation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No rota found for this week"})
+		return
+	}
+
+	rules, err := loadActiveRuleSet(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load rule set"})
+		return
+	}
+
+	var shifts []models.Shift
+	if err := database.DB.Preload("Officer", withFormerOfficers).
+		Where("date >= ? AND date <= ? AND status = ?", weekStart, weekStart.AddDate(0, 0, 6), models.StatusOnDuty).
+		Find(&shifts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shifts"})
+		return
+	}
+
+	shortfalls := weekCoverage(rules, weekStart, shifts)
+	c.JSON(http.StatusOK, gin.H{
+		"week_start": weekStart.Format("2006-01-02"),
+		"rule_set":   rules.Name,
+		"covered":    len(shortfalls) == 0,
+		"shortfalls": shortfalls,
+	})
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
-	"time"
 
 	"securityrota-api/database"
 	"securityrota-api/models"
@@ -50,96 +49,97 @@ func DownloadOfficersTemplate(c *gin.Context) {
 	writer := csv.NewWriter(c.Writer)
 	defer writer.Flush()
 
-	// Header
-	writer.Write([]string{"name", "role", "team"})
+	// Header; columns after team are optional
+	writer.Write([]string{"name", "role", "team", "badge_no", "rank", "gender", "phone", "email", "qualifications", "acting_sergeant"})
 
-	// Example rows
-	writer.Write([]string{"Sgt. Kalongana", "sergeant", "1"})
-	writer.Write([]string{"Faides", "female", "1"})
-	writer.Write([]string{"Abigail", "female", "1"})
-	writer.Write([]string{"Alexander", "regular", "1"})
-	writer.Write([]string{"Moses", "regular", "2"})
+	// Example rows; qualifications are "name:expiry" pairs separated by ";" (expiry optional)
+	writer.Write([]string{"Sgt. Kalongana", "sergeant", "1", "SGT001", "Sergeant", "male", "0977000001", "", "First Aid:2026-06-30;Firearms:2026-12-31", ""})
+	writer.Write([]string{"Faides", "regular", "1", "F001", "Constable", "female", "0977000002", "", "First Aid:2026-03-31", ""})
+	writer.Write([]string{"Abigail", "regular", "1", "F002", "Constable", "female", "", "", "", ""})
+	writer.Write([]string{"Alexander", "regular", "1", "R001", "Constable", "male", "", "", "Control Room", "yes"})
+	writer.Write([]string{"Moses", "regular", "2", "R002", "Constable", "male", "", "", "", "yes"})
 }
 
+// Columns of the shift and officer imports, in template order
+var (
+	shiftImportFields   = []string{"name", "date", "shift_type", "status"}
+	officerImportFields = []string{"name", "role", "team", "badge_no", "rank", "gender", "phone", "email", "qualifications", "acting_sergeant"}
+)
+
 // ImportShiftsCSV godoc
-// @Summary Import shifts from CSV file
-// @Description Upload a CSV file to bulk import shifts
+// @Summary Import shifts from a CSV or XLSX file
+// @Description Upload a CSV file or an Excel workbook (.xlsx) to bulk import shifts. Columns are found by header
+// @Description name (name, date, shift_type, status); columns maps them to other headers.
 // @Tags admin
 // @Accept multipart/form-data
 // @Produce json
-// @Param file formData file true "CSV file"
+// @Param file formData file true "CSV or XLSX file"
+// @Param sheet formData string false "XLSX sheet name or number (from 1), default the first"
+// @Param columns formData string false "JSON object of field to header name, e.g. {\"name\":\"Officer\"}"
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Router /admin/import-shifts/csv [post]
 func ImportShiftsCSV(c *gin.Context) {
-	file, err := c.FormFile("file")
+	table, err := readImportTable(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	f, err := file.Open()
+	columns, err := table.columns(c, shiftImportFields, len(shiftImportFields))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot open file"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	defer f.Close()
 
-	reader := csv.NewReader(f)
-	records, err := reader.ReadAll()
+	definitions, err := loadShiftDefinitions(database.DB)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV format"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shift definitions"})
 		return
 	}
 
-	if len(records) < 2 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV must have header and at least one data row"})
-		return
-	}
-
-	// Skip header row
 	var created, failed int
 	var errors []string
 
-	for i, row := range records[1:] {
-		if len(row) < 4 {
+	for i, row := range table.rows {
+		line := table.numbers[i]
+		if !columns.has(row, shiftImportFields...) {
 			failed++
-			errors = append(errors, fmt.Sprintf("Row %d: insufficient columns", i+2))
+			errors = append(errors, fmt.Sprintf("Row %d: insufficient columns", line))
 			continue
 		}
 
-		name := strings.TrimSpace(row[0])
-		dateStr := strings.TrimSpace(row[1])
-		shiftType := strings.TrimSpace(row[2])
-		status := strings.TrimSpace(row[3])
+		name := columns.get(row, "name")
+		dateStr := columns.get(row, "date")
+		shiftType := columns.get(row, "shift_type")
+		status := columns.get(row, "status")
 
 		// Find officer
 		var officer models.Officer
 		if err := database.DB.Where("name = ?", name).First(&officer).Error; err != nil {
 			failed++
-			errors = append(errors, fmt.Sprintf("Row %d: Officer not found: %s", i+2, name))
+			errors = append(errors, fmt.Sprintf("Row %d: Officer not found: %s", line, name))
 			continue
 		}
 
 		// Parse date
-		date, err := time.Parse("2006-01-02", dateStr)
+		date, err := parseImportDate(dateStr)
 		if err != nil {
 			failed++
-			errors = append(errors, fmt.Sprintf("Row %d: Invalid date format: %s", i+2, dateStr))
+			errors = append(errors, fmt.Sprintf("Row %d: Invalid date format: %s", line, dateStr))
 			continue
 		}
 
 		// Validate shift_type
-		if shiftType != "day" && shiftType != "night" {
+		if findShiftDefinition(definitions, models.ShiftType(shiftType)) == nil {
 			failed++
-			errors = append(errors, fmt.Sprintf("Row %d: Invalid shift_type: %s (use 'day' or 'night')", i+2, shiftType))
+			errors = append(errors, fmt.Sprintf("Row %d: Invalid shift_type: %s (use a shift definition code, e.g. 'day' or 'night')", line, shiftType))
 			continue
 		}
 
 		// Validate status
-		if status != "on_duty" && status != "off_duty" {
+		if status != "on_duty" && status != "off_duty" && status != "on_leave" {
 			failed++
-			errors = append(errors, fmt.Sprintf("Row %d: Invalid status: %s (use 'on_duty' or 'off_duty')", i+2, status))
+			errors = append(errors, fmt.Sprintf("Row %d: Invalid status: %s (use 'on_duty', 'off_duty' or 'on_leave')", line, status))
 			continue
 		}
 
@@ -152,7 +152,7 @@ func ImportShiftsCSV(c *gin.Context) {
 
 		if err := database.DB.Create(&shift).Error; err != nil {
 			failed++
-			errors = append(errors, fmt.Sprintf("Row %d: Failed to create shift", i+2))
+			errors = append(errors, fmt.Sprintf("Row %d: Failed to create shift (duplicate for %s %s?)", line, date.Format("2006-01-02"), shiftType))
 			continue
 		}
 		created++
@@ -166,80 +166,112 @@ func ImportShiftsCSV(c *gin.Context) {
 }
 
 // ImportOfficersCSV godoc
-// @Summary Import officers from CSV file
-// @Description Upload a CSV file to bulk import officers
+// @Summary Import officers from a CSV or XLSX file
+// @Description Upload a CSV file or an Excel workbook (.xlsx) to bulk import officers. Columns are found by header
+// @Description name as in the template; columns maps them to other headers.
 // @Tags admin
 // @Accept multipart/form-data
 // @Produce json
-// @Param file formData file true "CSV file"
+// @Param file formData file true "CSV or XLSX file"
+// @Param sheet formData string false "XLSX sheet name or number (from 1), default the first"
+// @Param columns formData string false "JSON object of field to header name, e.g. {\"name\":\"Officer\"}"
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Router /admin/import-officers/csv [post]
 func ImportOfficersCSV(c *gin.Context) {
-	file, err := c.FormFile("file")
+	table, err := readImportTable(c)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-
-	f, err := file.Open()
+	columns, err := table.columns(c, officerImportFields, 3)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot open file"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	defer f.Close()
 
-	reader := csv.NewReader(f)
-	records, err := reader.ReadAll()
+	cycle, err := loadActiveCycle(database.DB)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid CSV format"})
-		return
-	}
-
-	if len(records) < 2 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV must have header and at least one data row"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load rotation cycle"})
 		return
 	}
 
 	var created, failed int
 	var errors []string
 
-	for i, row := range records[1:] {
-		if len(row) < 3 {
+	for i, row := range table.rows {
+		line := table.numbers[i]
+		if !columns.has(row, officerImportFields[:3]...) {
 			failed++
-			errors = append(errors, fmt.Sprintf("Row %d: insufficient columns", i+2))
+			errors = append(errors, fmt.Sprintf("Row %d: insufficient columns", line))
 			continue
 		}
 
-		name := strings.TrimSpace(row[0])
-		role := strings.TrimSpace(row[1])
-		teamStr := strings.TrimSpace(row[2])
+		name := columns.get(row, "name")
+		role := strings.ToLower(columns.get(row, "role"))
+		teamStr := columns.get(row, "team")
+
+		// Older sheets recorded female officers with a "female" role
+		gender := strings.ToLower(columns.get(row, "gender"))
+		if role == "female" {
+			role = string(models.RoleRegular)
+			if gender == "" {
+				gender = string(models.GenderFemale)
+			}
+		}
 
 		// Validate role
-		if role != "sergeant" && role != "female" && role != "regular" {
+		if role != "sergeant" && role != "regular" {
 			failed++
-			errors = append(errors, fmt.Sprintf("Row %d: Invalid role: %s", i+2, role))
+			errors = append(errors, fmt.Sprintf("Row %d: Invalid role: %s", line, role))
 			continue
 		}
 
 		// Parse team
 		var team int
 		fmt.Sscanf(teamStr, "%d", &team)
-		if team != 1 && team != 2 {
+		if team < 1 || team > cycle.TeamCount {
 			failed++
-			errors = append(errors, fmt.Sprintf("Row %d: Invalid team: %s (use 1 or 2)", i+2, teamStr))
+			errors = append(errors, fmt.Sprintf("Row %d: Invalid team: %s (use 1 to %d)", line, teamStr, cycle.TeamCount))
 			continue
 		}
 
 		officer := models.Officer{
-			Name: name,
-			Role: models.OfficerRole(role),
-			Team: team,
+			Name:    name,
+			Role:    models.OfficerRole(role),
+			Team:    team,
+			BadgeNo: badgeNo(columns.get(row, "badge_no")),
+			Rank:    columns.get(row, "rank"),
+			Gender:  models.OfficerGender(gender),
+			Phone:   columns.get(row, "phone"),
+			Email:   columns.get(row, "email"),
+		}
+		switch strings.ToLower(columns.get(row, "acting_sergeant")) {
+		case "", "no", "false":
+		case "yes", "true":
+			officer.ActingSergeant = true
+		default:
+			failed++
+			errors = append(errors, fmt.Sprintf("Row %d: Invalid acting_sergeant: %s (use 'yes' or 'no')", line, columns.get(row, "acting_sergeant")))
+			continue
+		}
+		if officer.Gender != "" && officer.Gender != models.GenderFemale && officer.Gender != models.GenderMale {
+			failed++
+			errors = append(errors, fmt.Sprintf("Row %d: Invalid gender: %s (use 'female' or 'male')", line, officer.Gender))
+			continue
 		}
 
+		qualifications, err := parseQualifications(columns.get(row, "qualifications"))
+		if err != nil {
+			failed++
+			errors = append(errors, fmt.Sprintf("Row %d: %v", line, err))
+			continue
+		}
+		officer.Qualifications = qualifications
+
 		if err := database.DB.Create(&officer).Error; err != nil {
 			failed++
-			errors = append(errors, fmt.Sprintf("Row %d: Failed to create officer (duplicate name?)", i+2))
+			errors = append(errors, fmt.Sprintf("Row %d: Failed to create officer (duplicate name or badge number?)", line))
 			continue
 		}
 		created++
@@ -251,3 +283,33 @@ func ImportOfficersCSV(c *gin.Context) {
 		"errors":  errors,
 	})
 }
+
+// csvColumn returns a trimmed optional column, or "" when the row is shorter
+func csvColumn(row []string, i int) string {
+	if i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// parseQualifications parses "First Aid:2026-06-30;Firearms" into qualifications; the expiry is optional
+func parseQualifications(s string) ([]models.Qualification, error) {
+	var qualifications []models.Qualification
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, expiry, _ := strings.Cut(part, ":")
+		expiresOn, err := parseOptionalDate(strings.TrimSpace(expiry))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid expiry date for qualification %s: %s", name, expiry)
+		}
+		qualifications = append(qualifications, models.Qualification{
+			Name:      strings.TrimSpace(name),
+			ExpiresOn: expiresOn,
+		})
+	}
+	return qualifications, nil
+}
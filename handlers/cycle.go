@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CycleStepInput represents one team's assignment in one week of a cycle
+type CycleStepInput struct {
+	Week       int    `json:"week" binding:"min=0"`
+	Team       int    `json:"team" binding:"required,min=1"`
+	Assignment string `json:"assignment" binding:"required"` // A shift definition code, or rest
+}
+
+// CycleInput represents a complete rotation cycle; steps replace any existing ones
+type CycleInput struct {
+	Name      string           `json:"name" binding:"required"`
+	TeamCount int              `json:"team_count" binding:"required,min=1"`
+	Steps     []CycleStepInput `json:"steps" binding:"required,min=1,dive"`
+}
+
+// GetCycles godoc
+// @Summary Get rotation cycles
+// @Description Get all rotation cycles with their week-by-week team assignments
+// @Tags cycles
+// @Produce json
+// @Success 200 {array} models.RotationCycle
+// @Router /cycles [get]
+func GetCycles(c *gin.Context) {
+	var cycles []models.RotationCycle
+	database.DB.Preload("Steps", orderSteps).Order("id ASC").Find(&cycles)
+	c.JSON(http.StatusOK, cycles)
+}
+
+// GetCycle godoc
+// @Summary Get a rotation cycle by ID
+// @Description Get a single rotation cycle with its week-by-week team assignments
+// @Tags cycles
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Success 200 {object} models.RotationCycle
+// @Failure 404 {object} map[string]string
+// @Router /cycles/{id} [get]
+func GetCycle(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var cycle models.RotationCycle
+	if err := database.DB.Preload("Steps", orderSteps).First(&cycle, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cycle not found"})
+		return
+	}
+	c.JSON(http.StatusOK, cycle)
+}
+
+// CreateCycle godoc
+// @Summary Create a rotation cycle
+// @Description Create an inactive rotation cycle. Activate it to use it for generation.
+// @Tags cycles
+// @Accept json
+// @Produce json
+// @Param input body CycleInput true "Rotation cycle"
+// @Success 201 {object} models.RotationCycle
+// @Failure 400 {object} map[string]string
+// @Router /cycles [post]
+func CreateCycle(c *gin.Context) {
+	var input CycleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateCycleInput(database.DB, input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	cycle := models.RotationCycle{
+		Name:      input.Name,
+		TeamCount: input.TeamCount,
+		Steps:     input.steps(),
+	}
+
+	if err := database.DB.Create(&cycle).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create cycle (duplicate name?)"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, cycle)
+}
+
+// UpdateCycle godoc
+// @Summary Update a rotation cycle
+// @Description Replace a cycle's name, team count and steps
+// @Tags cycles
+// @Accept json
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Param input body CycleInput true "Rotation cycle"
+// @Success 200 {object} models.RotationCycle
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /cycles/{id} [put]
+func UpdateCycle(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var cycle models.RotationCycle
+	if err := database.DB.First(&cycle, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cycle not found"})
+		return
+	}
+
+	var input CycleInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateCycleInput(database.DB, input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&cycle).Updates(map[string]interface{}{
+			"name":       input.Name,
+			"team_count": input.TeamCount,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("cycle_id = ?", cycle.ID).Delete(&models.RotationCycleStep{}).Error; err != nil {
+			return err
+		}
+
+		steps := input.steps()
+		for i := range steps {
+			steps[i].CycleID = cycle.ID
+		}
+		return tx.Create(&steps).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update cycle: " + err.Error()})
+		return
+	}
+
+	database.DB.Preload("Steps", orderSteps).First(&cycle, cycle.ID)
+	c.JSON(http.StatusOK, cycle)
+}
+
+// DeleteCycle godoc
+// @Summary Delete a rotation cycle
+// @Description Delete an inactive rotation cycle
+// @Tags cycles
+// @Param id path int true "Cycle ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /cycles/{id} [delete]
+func DeleteCycle(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var cycle models.RotationCycle
+	if err := database.DB.First(&cycle, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cycle not found"})
+		return
+	}
+
+	if cycle.Active {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The active cycle cannot be deleted; activate another one first"})
+		return
+	}
+
+	database.DB.Select("Steps").Delete(&cycle)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ActivateCycle godoc
+// @Summary Activate a rotation cycle
+// @Description Make this cycle the one used by rota generation. The next generated week carries on from the latest
+// @Description earlier week: its position in this cycle plus the weeks since, or, if that week came from another cycle,
+// @Description the cycle week with the same day team. With no earlier week it starts at week 0.
+// @Tags cycles
+// @Produce json
+// @Param id path int true "Cycle ID"
+// @Success 200 {object} models.RotationCycle
+// @Failure 404 {object} map[string]string
+// @Router /cycles/{id}/activate [post]
+func ActivateCycle(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var cycle models.RotationCycle
+	if err := database.DB.First(&cycle, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Cycle not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.RotationCycle{}).Where("active = ?", true).Update("active", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&cycle).Update("active", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to activate cycle"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cycle)
+}
+
+// validateCycleInput checks that weeks are contiguous from 0, each team appears at most once per week
+// and every assignment is rest or a defined shift
+func validateCycleInput(db *gorm.DB, input CycleInput) error {
+	definitions, err := loadShiftDefinitions(db)
+	if err != nil {
+		return err
+	}
+
+	weeks := make(map[int]bool)
+	seen := make(map[string]bool)
+	maxWeek := 0
+	for i, s := range input.Steps {
+		if s.Assignment != models.CycleRest && findShiftDefinition(definitions, models.ShiftType(s.Assignment)) == nil {
+			return fmt.Errorf("step %d: unknown assignment %s; use rest or a shift definition code", i+1, s.Assignment)
+		}
+		if s.Team > input.TeamCount {
+			return fmt.Errorf("step %d: team %d exceeds team_count %d", i+1, s.Team, input.TeamCount)
+		}
+		key := fmt.Sprintf("%d/%d", s.Week, s.Team)
+		if seen[key] {
+			return fmt.Errorf("step %d: team %d has more than one assignment in week %d", i+1, s.Team, s.Week)
+		}
+		seen[key] = true
+		weeks[s.Week] = true
+		if s.Week > maxWeek {
+			maxWeek = s.Week
+		}
+	}
+
+	for w := 0; w <= maxWeek; w++ {
+		if !weeks[w] {
+			return fmt.Errorf("week %d has no steps; weeks must run from 0 without gaps", w)
+		}
+	}
+	return nil
+}
+
+func (input CycleInput) steps() []models.RotationCycleStep {
+	steps := make([]models.RotationCycleStep, 0, len(input.Steps))
+	for _, s := range input.Steps {
+		steps = append(steps, models.RotationCycleStep{
+			Week:       s.Week,
+			Team:       s.Team,
+			Assignment: s.Assignment,
+		})
+	}
+	return steps
+}
+
+func orderSteps(db *gorm.DB) *gorm.DB {
+	return db.Order("week ASC, team ASC")
+}
+
+// loadActiveCycle returns the active rotation cycle, falling back to the two-team alternation
+func loadActiveCycle(db *gorm.DB) (models.RotationCycle, error) {
+	var cycle models.RotationCycle
+	err := db.Preload("Steps", orderSteps).Where("active = ?", true).First(&cycle).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultRotationCycle(), nil
+	}
+	return cycle, err
+}
+
+// cycleWeekFor works out which week of the cycle a week falls on, continuing from the latest earlier
+// week's rotation and counting any weeks in between
+func cycleWeekFor(db *gorm.DB, cycle models.RotationCycle, weekStart time.Time) (int, error) {
+	length := cycle.Length()
+	if length == 0 {
+		return 0, nil
+	}
+
+	var prev models.WeekRotation
+	err := db.Where("week_start < ?", weekStart).Order("week_start DESC").First(&prev).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	elapsed := weeksBetween(prev.WeekStart, weekStart)
+
+	if prev.CycleID != nil && *prev.CycleID == cycle.ID {
+		return (prev.CycleWeek + elapsed) % length, nil
+	}
+
+	// Previous week came from another cycle or predates cycles: match on its day team
+	for w := 0; w < length; w++ {
+		if cycle.TeamOn(w, string(models.ShiftDay)) == prev.DayShiftTeam {
+			return (w + elapsed) % length, nil
+		}
+	}
+	return 0, nil
+}
+
+// weeksBetween counts whole weeks from one week start to a later one
+func weeksBetween(from, to time.Time) int {
+	days := int(math.Round(to.Sub(from).Hours() / 24))
+	return days / 7
+}
+
+// validateTeam checks a team number against the active rotation cycle
+func validateTeam(team int) error {
+	cycle, err := loadActiveCycle(database.DB)
+	if err != nil {
+		return fmt.Errorf("failed to load rotation cycle: %v", err)
+	}
+	if team < 1 || team > cycle.TeamCount {
+		return fmt.Errorf("team must be between 1 and %d", cycle.TeamCount)
+	}
+	return nil
+}
+
+// restTeams lists the teams resting in a week's assignments, in team order
+func restTeams(assignments map[int]string) []int {
+	teams := []int{}
+	for team := 1; team <= len(assignments); team++ {
+		if assignments[team] == models.CycleRest {
+			teams = append(teams, team)
+		}
+	}
+	return teams
+}
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HolidayInput represents a public holiday
+type HolidayInput struct {
+	Date string `json:"date" binding:"required"` // YYYY-MM-DD
+	Name string `json:"name" binding:"required"`
+}
+
+// GetHolidays godoc
+// @Summary Get public holidays
+// @Description Get public holidays, optionally for one year or between two dates
+// @Tags holidays
+// @Produce json
+// @Param year query int false "Calendar year"
+// @Param from query string false "First date (YYYY-MM-DD)"
+// @Param to query string false "Last date, inclusive (YYYY-MM-DD)"
+// @Success 200 {array} models.Holiday
+// @Router /holidays [get]
+func GetHolidays(c *gin.Context) {
+	query := database.DB.Order("date ASC")
+
+	if year, err := strconv.Atoi(c.Query("year")); err == nil {
+		start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+		query = query.Where("date >= ? AND date < ?", start, start.AddDate(1, 0, 0))
+	}
+	if from, err := time.Parse("2006-01-02", c.Query("from")); err == nil {
+		query = query.Where("date >= ?", from)
+	}
+	if to, err := time.Parse("2006-01-02", c.Query("to")); err == nil {
+		query = query.Where("date <= ?", to)
+	}
+
+	var holidays []models.Holiday
+	query.Find(&holidays)
+	c.JSON(http.StatusOK, holidays)
+}
+
+// GetHoliday godoc
+// @Summary Get a public holiday by ID
+// @Description Get a single public holiday by ID
+// @Tags holidays
+// @Produce json
+// @Param id path int true "Holiday ID"
+// @Success 200 {object} models.Holiday
+// @Failure 404 {object} map[string]string
+// @Router /holidays/{id} [get]
+func GetHoliday(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var holiday models.Holiday
+	if err := database.DB.First(&holiday, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Holiday not found"})
+		return
+	}
+	c.JSON(http.StatusOK, holiday)
+}
+
+// CreateHoliday godoc
+// @Summary Create a public holiday
+// @Description Add a public holiday. Weeks already generated are not changed; regenerate them to apply it.
+// @Tags holidays
+// @Accept json
+// @Produce json
+// @Param input body HolidayInput true "Holiday"
+// @Success 201 {object} models.Holiday
+// @Failure 400 {object} map[string]string
+// @Router /holidays [post]
+func CreateHoliday(c *gin.Context) {
+	var input HolidayInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+		return
+	}
+
+	holiday := models.Holiday{Date: date, Name: input.Name}
+	if err := database.DB.Create(&holiday).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create holiday (already a holiday on that date?)"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, holiday)
+}
+
+// UpdateHoliday godoc
+// @Summary Update a public holiday
+// @Description Change a public holiday's date or name
+// @Tags holidays
+// @Accept json
+// @Produce json
+// @Param id path int true "Holiday ID"
+// @Param input body HolidayInput true "Holiday"
+// @Success 200 {object} models.Holiday
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /holidays/{id} [put]
+func UpdateHoliday(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var holiday models.Holiday
+	if err := database.DB.First(&holiday, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Holiday not found"})
+		return
+	}
+
+	var input HolidayInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+		return
+	}
+
+	err = database.DB.Model(&holiday).Updates(map[string]interface{}{"date": date, "name": input.Name}).Error
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update holiday (already a holiday on that date?)"})
+		return
+	}
+
+	c.JSON(http.StatusOK, holiday)
+}
+
+// DeleteHoliday godoc
+// @Summary Delete a public holiday
+// @Description Remove a public holiday
+// @Tags holidays
+// @Param id path int true "Holiday ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /holidays/{id} [delete]
+func DeleteHoliday(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var holiday models.Holiday
+	if err := database.DB.First(&holiday, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Holiday not found"})
+		return
+	}
+
+	database.DB.Delete(&holiday)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ImportHolidays godoc
+// @Summary Import public holidays
+// @Description Upload an iCalendar (.ics) file or a CSV file with date,name columns. Each event or row
+// @Description becomes a holiday; multi-day events add every day. A date that is already a holiday is renamed.
+// @Tags holidays
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "ICS or CSV file"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /holidays/import [post]
+func ImportHolidays(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file uploaded"})
+		return
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot open file"})
+		return
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot read file"})
+		return
+	}
+
+	var holidays []models.Holiday
+	var errors []string
+	if strings.EqualFold(filepath.Ext(file.Filename), ".ics") || bytes.HasPrefix(bytes.TrimSpace(data), []byte("BEGIN:VCALENDAR")) {
+		holidays, errors = parseHolidaysICS(data)
+	} else {
+		holidays, errors, err = parseHolidaysCSV(data)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var created, updated int
+	failed := len(errors)
+	for _, holiday := range holidays {
+		var existing models.Holiday
+		err := database.DB.Where("date = ?", holiday.Date).First(&existing).Error
+		if err == nil {
+			if err := database.DB.Model(&existing).Update("name", holiday.Name).Error; err != nil {
+				failed++
+				errors = append(errors, fmt.Sprintf("%s: Failed to update holiday", holiday.Date.Format("2006-01-02")))
+				continue
+			}
+			updated++
+			continue
+		}
+		if err != gorm.ErrRecordNotFound {
+			failed++
+			errors = append(errors, fmt.Sprintf("%s: Failed to look up holiday", holiday.Date.Format("2006-01-02")))
+			continue
+		}
+		if err := database.DB.Create(&holiday).Error; err != nil {
+			failed++
+			errors = append(errors, fmt.Sprintf("%s: Failed to create holiday", holiday.Date.Format("2006-01-02")))
+			continue
+		}
+		created++
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"created": created,
+		"updated": updated,
+		"failed":  failed,
+		"errors":  errors,
+	})
+}
+
+// parseHolidaysCSV reads date,name rows after a header row
+func parseHolidaysCSV(data []byte) ([]models.Holiday, []string, error) {
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid CSV format")
+	}
+	if len(records) < 2 {
+		return nil, nil, fmt.Errorf("CSV must have header and at least one data row")
+	}
+
+	var holidays []models.Holiday
+	var errors []string
+	for i, row := range records[1:] {
+		if len(row) < 2 {
+			errors = append(errors, fmt.Sprintf("Row %d: insufficient columns", i+2))
+			continue
+		}
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(row[0]))
+		if err != nil {
+			errors = append(errors, fmt.Sprintf("Row %d: Invalid date format: %s", i+2, row[0]))
+			continue
+		}
+		name := strings.TrimSpace(row[1])
+		if name == "" {
+			errors = append(errors, fmt.Sprintf("Row %d: name is required", i+2))
+			continue
+		}
+		holidays = append(holidays, models.Holiday{Date: date, Name: name})
+	}
+	return holidays, errors, nil
+}
+
+// parseHolidaysICS reads the all-day events of an iCalendar file. DTEND is exclusive, as in RFC 5545.
+func parseHolidaysICS(data []byte) ([]models.Holiday, []string) {
+	// Unfold continuation lines, which start with a space or tab
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	var holidays []models.Holiday
+	var errors []string
+	var inEvent bool
+	var summary, start, end string
+	event := 0
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Drop parameters such as DTSTART;VALUE=DATE
+		property, _, _ := strings.Cut(strings.ToUpper(name), ";")
+
+		switch {
+		case property == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent = true
+			summary, start, end = "", "", ""
+			event++
+		case !inEvent:
+		case property == "SUMMARY":
+			summary = icsUnescape(value)
+		case property == "DTSTART":
+			start = value
+		case property == "DTEND":
+			end = value
+		case property == "END" && strings.EqualFold(value, "VEVENT"):
+			inEvent = false
+			first, err := parseICSDate(start)
+			if err != nil {
+				errors = append(errors, fmt.Sprintf("Event %d: Invalid DTSTART: %s", event, start))
+				continue
+			}
+			if summary == "" {
+				errors = append(errors, fmt.Sprintf("Event %d: SUMMARY is required", event))
+				continue
+			}
+			last := first
+			if end != "" {
+				if until, err := parseICSDate(end); err == nil && until.After(first) {
+					last = until.AddDate(0, 0, -1)
+				}
+			}
+			for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+				holidays = append(holidays, models.Holiday{Date: d, Name: summary})
+			}
+		}
+	}
+	return holidays, errors
+}
+
+// parseICSDate parses a DATE or DATE-TIME value, keeping only the date
+func parseICSDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date: %s", value)
+	}
+	return time.Parse("20060102", value[:8])
+}
+
+// icsUnescape reverses iCalendar TEXT escaping
+func icsUnescape(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(strings.TrimSpace(s))
+}
+
+// loadHolidays returns the names of public holidays from from to to (inclusive), keyed by YYYY-MM-DD
+func loadHolidays(db *gorm.DB, from, to time.Time) (map[string]string, error) {
+	var holidays []models.Holiday
+	if err := db.Where("date >= ? AND date <= ?", from, to).Find(&holidays).Error; err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]string, len(holidays))
+	for _, holiday := range holidays {
+		byDate[holiday.Date.Format("2006-01-02")] = holiday.Name
+	}
+	return byDate, nil
+}
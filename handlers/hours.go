@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Night hours are those worked between nightHoursStart and nightHoursEnd (hours of the day)
+const (
+	nightHoursStart = 22
+	nightHoursEnd   = 6
+)
+
+// OfficerHours represents the hours one officer worked over a period
+type OfficerHours struct {
+	OfficerID       uint    `json:"officer_id"`
+	Name            string  `json:"name"`
+	Team            int     `json:"team"`
+	Shifts          int     `json:"shifts"`           // On-duty shifts started in the period
+	Hours           float64 `json:"hours"`            // Total hours of those shifts
+	NightHours      float64 `json:"night_hours"`      // Hours between 22:00 and 06:00
+	WeekendHours    float64 `json:"weekend_hours"`    // Hours on Saturdays and Sundays
+	HolidayHours    float64 `json:"holiday_hours"`    // Hours on public holidays
+	ContractedHours float64 `json:"contracted_hours"` // Weekly contract pro rata for the days employed in the period
+	Overtime        float64 `json:"overtime"`         // Hours above the contracted hours
+}
+
+// GetHoursReport godoc
+// @Summary Get hours worked
+// @Description Get the hours each officer worked on duty over a period, with night, weekend and public holiday
+// @Description hours and overtime over their contracted hours. Defaults to the current month.
+// @Tags rota
+// @Produce json
+// @Param month query string false "Month (YYYY-MM), instead of from and to"
+// @Param from query string false "First date (YYYY-MM-DD)"
+// @Param to query string false "Last date, inclusive (YYYY-MM-DD)"
+// @Param officer_id query int false "Only this officer"
+// @Param team query int false "Only officers of this team"
+// @Success 200 {array} OfficerHours
+// @Failure 400 {object} map[string]string
+// @Router /rota/hours [get]
+func GetHoursReport(c *gin.Context) {
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, -1)
+
+	var err error
+	if s := c.Query("month"); s != "" {
+		if from, err = time.Parse("2006-01", s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month, use YYYY-MM"})
+			return
+		}
+		to = from.AddDate(0, 1, -1)
+	}
+	if s := c.Query("from"); s != "" {
+		if from, err = time.Parse("2006-01-02", s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, use YYYY-MM-DD"})
+			return
+		}
+	}
+	if s := c.Query("to"); s != "" {
+		if to, err = time.Parse("2006-01-02", s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, use YYYY-MM-DD"})
+			return
+		}
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+
+	// Officers who have left are included while they have shifts in the period
+	worked := database.DB.Model(&models.Shift{}).Select("officer_id").Where("date >= ? AND date <= ?", from, to)
+	query := database.DB.Unscoped().Where("deleted_at IS NULL OR id IN (?)", worked)
+	if s := c.Query("officer_id"); s != "" {
+		officerID, err := strconv.Atoi(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid officer_id"})
+			return
+		}
+		query = query.Where("id = ?", officerID)
+	}
+	if s := c.Query("team"); s != "" {
+		team, err := strconv.Atoi(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid team"})
+			return
+		}
+		query = query.Where("team = ?", team)
+	}
+
+	var officers []models.Officer
+	if err := query.Order("team ASC, name ASC").Find(&officers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load officers"})
+		return
+	}
+
+	var shifts []models.Shift
+	if err := database.DB.Where("date >= ? AND date <= ? AND status = ?", from, to, models.StatusOnDuty).
+		Find(&shifts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shifts"})
+		return
+	}
+
+	definitions, err := loadShiftDefinitions(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shift definitions"})
+		return
+	}
+
+	// Night shifts run into the day after the period, which may be a holiday
+	holidays, err := loadHolidays(database.DB, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load holidays"})
+		return
+	}
+
+	byOfficer := make(map[uint]*OfficerHours, len(officers))
+	result := make([]OfficerHours, len(officers))
+	for i, officer := range officers {
+		days := 0
+		for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+			if officer.ActiveOn(d) {
+				days++
+			}
+		}
+		result[i] = OfficerHours{
+			OfficerID:       officer.ID,
+			Name:            officer.Name,
+			Team:            officer.Team,
+			ContractedHours: officer.ContractedHours * float64(days) / 7,
+		}
+		byOfficer[officer.ID] = &result[i]
+	}
+
+	for _, shift := range shifts {
+		entry, ok := byOfficer[shift.OfficerID]
+		if !ok {
+			continue
+		}
+		definition := findShiftDefinition(definitions, shift.ShiftType)
+		if definition == nil {
+			continue
+		}
+		entry.Shifts++
+		addShiftHours(entry, *definition, shift.Date, holidays)
+	}
+
+	for i := range result {
+		entry := &result[i]
+		entry.Overtime = math.Max(0, entry.Hours-entry.ContractedHours)
+		for _, hours := range []*float64{&entry.Hours, &entry.NightHours, &entry.WeekendHours, &entry.HolidayHours, &entry.ContractedHours, &entry.Overtime} {
+			*hours = math.Round(*hours*100) / 100
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// addShiftHours adds a shift's hours to an officer's totals, splitting it at midnight and at the start and
+// end of night hours so each part is counted by the day and time it was worked
+func addShiftHours(entry *OfficerHours, definition models.ShiftDefinition, date time.Time, holidays map[string]string) {
+	start, err := time.Parse("15:04", definition.StartTime)
+	if err != nil {
+		return
+	}
+	from := date.Add(time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute)
+	end := from.Add(time.Duration(definition.Hours() * float64(time.Hour)))
+
+	for from.Before(end) {
+		midnight := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+		next := midnight.AddDate(0, 0, 1)
+		for _, hour := range []int{nightHoursEnd, nightHoursStart} {
+			if boundary := midnight.Add(time.Duration(hour) * time.Hour); boundary.After(from) && boundary.Before(next) {
+				next = boundary
+			}
+		}
+		if next.After(end) {
+			next = end
+		}
+
+		hours := next.Sub(from).Hours()
+		entry.Hours += hours
+		if from.Hour() >= nightHoursStart || from.Hour() < nightHoursEnd {
+			entry.NightHours += hours
+		}
+		if from.Weekday() == time.Saturday || from.Weekday() == time.Sunday {
+			entry.WeekendHours += hours
+		}
+		if holidays[from.Format("2006-01-02")] != "" {
+			entry.HolidayHours += hours
+		}
+		from = next
+	}
+}
@@ -13,7 +13,7 @@ import (
 // ImportCurrentStateInput represents the current manual schedule state
 type ImportCurrentStateInput struct {
 	WeekStart    string `json:"week_start" binding:"required"`     // Current week's Sunday (YYYY-MM-DD)
-	DayShiftTeam int    `json:"day_shift_team" binding:"required"` // Which team (1 or 2) is currently on day shift
+	DayShiftTeam int    `json:"day_shift_team" binding:"required"` // Which team is currently on day shift
 }
 
 // ImportCurrentState godoc
@@ -44,28 +44,45 @@ func ImportCurrentState(c *gin.Context) {
 		return
 	}
 
-	if input.DayShiftTeam != 1 && input.DayShiftTeam != 2 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "day_shift_team must be 1 or 2"})
+	// Find the week of the active cycle with this team on days
+	cycle, err := loadActiveCycle(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load rotation cycle"})
+		return
+	}
+	cycleWeek := -1
+	for w := 0; w < cycle.Length(); w++ {
+		if cycle.TeamOn(w, string(models.ShiftDay)) == input.DayShiftTeam {
+			cycleWeek = w
+			break
+		}
+	}
+	if cycleWeek < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "day_shift_team is never on day shift in the active rotation cycle"})
 		return
 	}
 
 	// Check if rotation already exists
 	var existing models.WeekRotation
 	if database.DB.Where("week_start = ?", weekStart).First(&existing).Error == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Rotation already exists for this week. Delete it first if you want to re-import."})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rotation already exists for this week. Delete it first with DELETE /shifts/week if you want to re-import."})
 		return
 	}
 
 	// Create the rotation record to establish current state
+	nightShiftTeam := cycle.TeamOn(cycleWeek, string(models.ShiftNight))
 	rotation := models.WeekRotation{
-		WeekStart:    weekStart,
-		DayShiftTeam: input.DayShiftTeam,
+		WeekStart:      weekStart,
+		DayShiftTeam:   input.DayShiftTeam,
+		NightShiftTeam: nightShiftTeam,
+		CycleWeek:      cycleWeek,
 	}
-	database.DB.Create(&rotation)
-
-	nightShiftTeam := 1
-	if input.DayShiftTeam == 1 {
-		nightShiftTeam = 2
+	if cycle.ID > 0 {
+		rotation.CycleID = &cycle.ID
+	}
+	if err := database.DB.Create(&rotation).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save rotation"})
+		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
@@ -73,6 +90,7 @@ func ImportCurrentState(c *gin.Context) {
 		"week_start":       input.WeekStart,
 		"day_shift_team":   input.DayShiftTeam,
 		"night_shift_team": nightShiftTeam,
+		"cycle_week":       cycleWeek,
 		"next_step":        "Now you can generate future weeks using POST /shifts/generate",
 	})
 }
@@ -81,8 +99,8 @@ func ImportCurrentState(c *gin.Context) {
 type BulkImportShiftInput struct {
 	Name      string `json:"name" binding:"required"`       // Officer name
 	Date      string `json:"date" binding:"required"`       // YYYY-MM-DD
-	ShiftType string `json:"shift_type" binding:"required"` // day or night
-	Status    string `json:"status" binding:"required"`     // on_duty or off_duty
+	ShiftType string `json:"shift_type" binding:"required"` // Shift definition code, e.g. day or night
+	Status    string `json:"status" binding:"required"`     // on_duty, off_duty or on_leave
 }
 
 // BulkImportShiftsInput represents bulk import request
@@ -107,6 +125,12 @@ func BulkImportShifts(c *gin.Context) {
 		return
 	}
 
+	definitions, err := loadShiftDefinitions(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shift definitions"})
+		return
+	}
+
 	var created, failed int
 	var errors []string
 
@@ -126,6 +150,12 @@ func BulkImportShifts(c *gin.Context) {
 			continue
 		}
 
+		if findShiftDefinition(definitions, models.ShiftType(s.ShiftType)) == nil {
+			failed++
+			errors = append(errors, "Invalid shift_type for "+s.Name+": "+s.ShiftType)
+			continue
+		}
+
 		shift := models.Shift{
 			OfficerID: officer.ID,
 			Date:      date,
@@ -135,7 +165,7 @@ func BulkImportShifts(c *gin.Context) {
 
 		if err := database.DB.Create(&shift).Error; err != nil {
 			failed++
-			errors = append(errors, "Failed to create shift for "+s.Name)
+			errors = append(errors, "Failed to create shift for "+s.Name+" (duplicate shift for "+s.Date+"?)")
 			continue
 		}
 		created++
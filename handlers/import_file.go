@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"mime/multipart"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/unidoc/unioffice/spreadsheet"
+)
+
+// importTable is an uploaded CSV file or XLSX sheet: its header and data rows, with each row's line number
+// in the file for error messages
+type importTable struct {
+	header  []string
+	rows    [][]string
+	numbers []int
+}
+
+// importColumns maps import field names to column positions
+type importColumns map[string]int
+
+// readImportTable reads the uploaded "file" form field. .xlsx uploads read the sheet named or numbered
+// (from 1) by the "sheet" form field, by default the first.
+func readImportTable(c *gin.Context) (importTable, error) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return importTable{}, errors.New("No file uploaded")
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return importTable{}, errors.New("Cannot open file")
+	}
+	defer f.Close()
+
+	var table importTable
+	if strings.EqualFold(filepath.Ext(file.Filename), ".xlsx") {
+		table, err = readXLSXTable(f, file.Size, c.PostForm("sheet"))
+	} else {
+		table, err = readCSVTable(f)
+	}
+	if err != nil {
+		return table, err
+	}
+
+	if len(table.rows) == 0 {
+		return table, errors.New("File must have header and at least one data row")
+	}
+	return table, nil
+}
+
+func readCSVTable(f multipart.File) (importTable, error) {
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return importTable{}, errors.New("Invalid CSV format")
+	}
+
+	var table importTable
+	for i, record := range records {
+		if i == 0 {
+			table.header = record
+			continue
+		}
+		table.rows = append(table.rows, record)
+		table.numbers = append(table.numbers, i+1)
+	}
+	return table, nil
+}
+
+func readXLSXTable(f multipart.File, size int64, sheetName string) (importTable, error) {
+	wb, err := spreadsheet.Read(f, size)
+	if err != nil {
+		return importTable{}, fmt.Errorf("Cannot read XLSX file: %v", err)
+	}
+	defer wb.Close()
+
+	sheets := wb.Sheets()
+	if len(sheets) == 0 {
+		return importTable{}, errors.New("Workbook has no sheets")
+	}
+
+	sheet := sheets[0]
+	if sheetName != "" {
+		found := false
+		if n, err := strconv.Atoi(sheetName); err == nil && n >= 1 && n <= len(sheets) {
+			sheet, found = sheets[n-1], true
+		}
+		var names []string
+		for _, s := range sheets {
+			names = append(names, s.Name())
+			if !found && strings.EqualFold(s.Name(), sheetName) {
+				sheet, found = s, true
+			}
+		}
+		if !found {
+			return importTable{}, fmt.Errorf("Sheet not found: %s (workbook has %s)", sheetName, strings.Join(names, ", "))
+		}
+	}
+
+	var table importTable
+	lastColumn := sheet.MaxColumnIdx()
+	for _, row := range sheet.Rows() {
+		var values []string
+		empty := true
+		for _, cell := range row.CellsWithEmpty(lastColumn) {
+			value := strings.TrimSpace(cell.GetString())
+			if cell.IsBool() {
+				b, _ := cell.GetValueAsBool()
+				value = strconv.FormatBool(b)
+			}
+			if value != "" {
+				empty = false
+			}
+			values = append(values, value)
+		}
+		if empty {
+			continue
+		}
+
+		if table.header == nil {
+			table.header = values
+			continue
+		}
+		table.rows = append(table.rows, values)
+		table.numbers = append(table.numbers, int(row.RowNumber()))
+	}
+	return table, nil
+}
+
+// columns finds each field's column by header name, ignoring case, spaces and underscores. The "columns" form
+// field may map fields to other header names as JSON, e.g. {"name":"Officer","date":"Duty Date"}. A header
+// naming none of the fields is taken to be in field order, as in the CSV templates.
+func (t importTable) columns(c *gin.Context, fields []string, required int) (importColumns, error) {
+	mapping := map[string]string{}
+	if s := c.PostForm("columns"); s != "" {
+		if err := json.Unmarshal([]byte(s), &mapping); err != nil {
+			return nil, errors.New("columns must be a JSON object of field names to header names")
+		}
+	}
+
+	normalize := func(s string) string {
+		return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(strings.TrimSpace(s)))
+	}
+	byHeader := make(map[string]int, len(t.header))
+	for i, h := range t.header {
+		if _, ok := byHeader[normalize(h)]; !ok {
+			byHeader[normalize(h)] = i
+		}
+	}
+
+	columns := make(importColumns, len(fields))
+	for _, field := range fields {
+		header := field
+		if mapped, ok := mapping[field]; ok {
+			header = mapped
+		}
+		if i, ok := byHeader[normalize(header)]; ok {
+			columns[field] = i
+		} else if _, ok := mapping[field]; ok {
+			return nil, fmt.Errorf("Column not found: %s", header)
+		}
+	}
+
+	if len(columns) == 0 {
+		for i, field := range fields {
+			columns[field] = i
+		}
+		return columns, nil
+	}
+	for _, field := range fields[:required] {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("Missing column: %s (name it in the columns field if the header differs)", field)
+		}
+	}
+	return columns, nil
+}
+
+// get returns a field's trimmed value, or "" when the row has no such column
+func (columns importColumns) get(row []string, field string) string {
+	i, ok := columns[field]
+	if !ok {
+		return ""
+	}
+	return csvColumn(row, i)
+}
+
+// has reports whether the row reaches every one of the fields' columns
+func (columns importColumns) has(row []string, fields ...string) bool {
+	for _, field := range fields {
+		if columns[field] >= len(row) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseImportDate parses a YYYY-MM-DD date, or the day number Excel stores for date cells
+func parseImportDate(s string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", s); err == nil {
+		return date, nil
+	}
+	serial, err := strconv.ParseFloat(s, 64)
+	if err != nil || serial < 1 {
+		return time.Time{}, errors.New("invalid date")
+	}
+	return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(math.Floor(serial))), nil
+}
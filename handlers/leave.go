@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateLeaveInput represents the input for recording leave
+type CreateLeaveInput struct {
+	OfficerID uint             `json:"officer_id" binding:"required"`
+	StartDate string           `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate   string           `json:"end_date" binding:"required"`   // YYYY-MM-DD (inclusive)
+	Type      models.LeaveType `json:"type" binding:"required,oneof=annual sick training other"`
+	Reason    string           `json:"reason"`
+}
+
+// UpdateLeaveInput represents the input for updating a pending leave request
+type UpdateLeaveInput struct {
+	StartDate string           `json:"start_date"` // YYYY-MM-DD
+	EndDate   string           `json:"end_date"`   // YYYY-MM-DD (inclusive)
+	Type      models.LeaveType `json:"type" binding:"omitempty,oneof=annual sick training other"`
+	Reason    string           `json:"reason"`
+}
+
+// GetLeavesInput represents query params for listing leave
+type GetLeavesInput struct {
+	OfficerID uint   `form:"officer_id"`
+	Status    string `form:"status"`
+	From      string `form:"from"` // YYYY-MM-DD
+	To        string `form:"to"`   // YYYY-MM-DD
+}
+
+// GetLeaves godoc
+// @Summary Get leave records
+// @Description Get leave records with optional filters (officer_id, status, from, to)
+// @Tags leave
+// @Produce json
+// @Param officer_id query int false "Officer ID"
+// @Param status query string false "pending, approved or rejected"
+// @Param from query string false "Overlapping from date (YYYY-MM-DD)"
+// @Param to query string false "Overlapping to date (YYYY-MM-DD)"
+// @Success 200 {array} models.Leave
+// @Router /leave [get]
+func GetLeaves(c *gin.Context) {
+	var input GetLeavesInput
+	c.ShouldBindQuery(&input)
+
+	query := database.DB.Preload("Officer", withFormerOfficers)
+
+	if input.OfficerID > 0 {
+		query = query.Where("officer_id = ?", input.OfficerID)
+	}
+
+	if input.Status != "" {
+		query = query.Where("status = ?", input.Status)
+	}
+
+	if input.From != "" {
+		from, _ := time.Parse("2006-01-02", input.From)
+		query = query.Where("end_date >= ?", from)
+	}
+
+	if input.To != "" {
+		to, _ := time.Parse("2006-01-02", input.To)
+		query = query.Where("start_date <= ?", to)
+	}
+
+	var leaves []models.Leave
+	query.Order("start_date ASC").Find(&leaves)
+	c.JSON(http.StatusOK, leaves)
+}
+
+// GetLeave godoc
+// @Summary Get a leave record by ID
+// @Description Get a single leave record by ID
+// @Tags leave
+// @Produce json
+// @Param id path int true "Leave ID"
+// @Success 200 {object} models.Leave
+// @Failure 404 {object} map[string]string
+// @Router /leave/{id} [get]
+func GetLeave(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var leave models.Leave
+	if err := database.DB.Preload("Officer", withFormerOfficers).First(&leave, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave not found"})
+		return
+	}
+	c.JSON(http.StatusOK, leave)
+}
+
+// CreateLeave godoc
+// @Summary Record leave
+// @Description Record annual leave, sick leave or training for an officer. New leave is pending until approved.
+// @Tags leave
+// @Accept json
+// @Produce json
+// @Param input body CreateLeaveInput true "Leave data"
+// @Success 201 {object} models.Leave
+// @Failure 400 {object} map[string]string
+// @Router /leave [post]
+func CreateLeave(c *gin.Context) {
+	var input CreateLeaveInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	startDate, endDate, ok := parseLeaveDates(c, input.StartDate, input.EndDate)
+	if !ok {
+		return
+	}
+
+	var officer models.Officer
+	if err := database.DB.First(&officer, input.OfficerID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Officer not found"})
+		return
+	}
+
+	leave := models.Leave{
+		OfficerID: officer.ID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Type:      input.Type,
+		Status:    models.LeavePending,
+		Reason:    input.Reason,
+	}
+
+	if err := database.DB.Create(&leave).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	leave.Officer = officer
+	c.JSON(http.StatusCreated, leave)
+}
+
+// UpdateLeave godoc
+// @Summary Update a leave request
+// @Description Update a pending leave request
+// @Tags leave
+// @Accept json
+// @Produce json
+// @Param id path int true "Leave ID"
+// @Param input body UpdateLeaveInput true "Leave data"
+// @Success 200 {object} models.Leave
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /leave/{id} [put]
+func UpdateLeave(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var leave models.Leave
+	if err := database.DB.First(&leave, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave not found"})
+		return
+	}
+
+	if leave.Status != models.LeavePending {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only pending leave can be edited"})
+		return
+	}
+
+	var input UpdateLeaveInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	startStr := leave.StartDate.Format("2006-01-02")
+	if input.StartDate != "" {
+		startStr = input.StartDate
+	}
+	endStr := leave.EndDate.Format("2006-01-02")
+	if input.EndDate != "" {
+		endStr = input.EndDate
+	}
+
+	startDate, endDate, ok := parseLeaveDates(c, startStr, endStr)
+	if !ok {
+		return
+	}
+
+	database.DB.Model(&leave).Updates(models.Leave{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Type:      input.Type,
+		Reason:    input.Reason,
+	})
+	c.JSON(http.StatusOK, leave)
+}
+
+// DeleteLeave godoc
+// @Summary Delete a leave record
+// @Description Delete a leave record. Shifts marked on leave by an approved record get back their rostered status.
+// @Tags leave
+// @Param id path int true "Leave ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /leave/{id} [delete]
+func DeleteLeave(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var leave models.Leave
+	if err := database.DB.First(&leave, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&leave).Error; err != nil {
+			return err
+		}
+		if leave.Status == models.LeaveApproved {
+			return releaseLeaveShifts(tx, leave)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete leave"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ApproveLeave godoc
+// @Summary Approve leave
+// @Description Approve a leave request and mark any already generated shifts in the range as on leave
+// @Tags leave
+// @Produce json
+// @Param id path int true "Leave ID"
+// @Success 200 {object} models.Leave
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /leave/{id}/approve [post]
+func ApproveLeave(c *gin.Context) {
+	reviewLeave(c, models.LeaveApproved)
+}
+
+// RejectLeave godoc
+// @Summary Reject leave
+// @Description Reject a leave request. Rejecting approved leave gives its shifts back their rostered status.
+// @Tags leave
+// @Produce json
+// @Param id path int true "Leave ID"
+// @Success 200 {object} models.Leave
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /leave/{id}/reject [post]
+func RejectLeave(c *gin.Context) {
+	reviewLeave(c, models.LeaveRejected)
+}
+
+// reviewLeave moves a leave record to the given approval state
+func reviewLeave(c *gin.Context, status models.LeaveStatus) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var leave models.Leave
+	if err := database.DB.First(&leave, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Leave not found"})
+		return
+	}
+
+	if leave.Status == status {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Leave is already " + string(status)})
+		return
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{
+		"status":      status,
+		"reviewed_at": now,
+	}
+	if userID, exists := c.Get("userID"); exists {
+		updates["reviewed_by"] = userID.(uint)
+	}
+	// The decision and its effect on the officer's shifts are saved together
+	wasApproved := leave.Status == models.LeaveApproved
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&leave).Updates(updates).Error; err != nil {
+			return err
+		}
+		if status == models.LeaveApproved {
+			return tx.Model(&models.Shift{}).
+				Where("officer_id = ? AND date >= ? AND date <= ? AND status <> ?",
+					leave.OfficerID, leave.StartDate, leave.EndDate, models.StatusOnLeave).
+				Updates(map[string]interface{}{
+					"status":              models.StatusOnLeave,
+					"status_before_leave": gorm.Expr("status"),
+				}).Error
+		}
+		if wasApproved {
+			return releaseLeaveShifts(tx, leave)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update leave"})
+		return
+	}
+
+	c.JSON(http.StatusOK, leave)
+}
+
+// releaseLeaveShifts gives shifts marked on leave for this record back the status they were rostered with,
+// or off duty if it was not recorded. Dates still covered by another approved leave stay on leave.
+func releaseLeaveShifts(tx *gorm.DB, leave models.Leave) error {
+	var shifts []models.Shift
+	if err := tx.Where("officer_id = ? AND date >= ? AND date <= ? AND status = ?",
+		leave.OfficerID, leave.StartDate, leave.EndDate, models.StatusOnLeave).
+		Find(&shifts).Error; err != nil {
+		return err
+	}
+
+	var others []models.Leave
+	approved, err := loadApprovedLeaves(tx, leave.StartDate, leave.EndDate)
+	if err != nil {
+		return err
+	}
+	for _, l := range approved {
+		if l.ID != leave.ID {
+			others = append(others, l)
+		}
+	}
+
+	for _, shift := range shifts {
+		if findLeave(others, shift.OfficerID, shift.Date) != nil {
+			continue
+		}
+		status := shift.StatusBeforeLeave
+		if status == "" {
+			status = models.StatusOffDuty
+		}
+		if err := tx.Model(&shift).Updates(map[string]interface{}{
+			"status":              status,
+			"status_before_leave": "",
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseLeaveDates parses and validates an inclusive leave date range, writing a 400 on failure
+func parseLeaveDates(c *gin.Context, startStr, endStr string) (time.Time, time.Time, bool) {
+	startDate, err := time.Parse("2006-01-02", startStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date format, use YYYY-MM-DD"})
+		return time.Time{}, time.Time{}, false
+	}
+
+	endDate, err := time.Parse("2006-01-02", endStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date format, use YYYY-MM-DD"})
+		return time.Time{}, time.Time{}, false
+	}
+
+	if endDate.Before(startDate) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_date must not be before start_date"})
+		return time.Time{}, time.Time{}, false
+	}
+
+	return startDate, endDate, true
+}
+
+// loadApprovedLeaves returns approved leave overlapping the inclusive date range
+func loadApprovedLeaves(db *gorm.DB, from, to time.Time) ([]models.Leave, error) {
+	var leaves []models.Leave
+	err := db.Where("status = ? AND start_date <= ? AND end_date >= ?", models.LeaveApproved, to, from).
+		Find(&leaves).Error
+	return leaves, err
+}
+
+// findLeave returns the approved leave covering an officer on a date, if any
+func findLeave(leaves []models.Leave, officerID uint, date time.Time) *models.Leave {
+	for i := range leaves {
+		if leaves[i].OfficerID == officerID && leaves[i].Covers(date) {
+			return &leaves[i]
+		}
+	}
+	return nil
+}
+
+// leaveCode returns the short code shown for a leave type in rota exports
+func leaveCode(leaveType models.LeaveType) string {
+	switch leaveType {
+	case models.LeaveAnnual:
+		return "AL"
+	case models.LeaveSick:
+		return "SL"
+	case models.LeaveTraining:
+		return "TRG"
+	default:
+		return "LV"
+	}
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// offDayLookbackWeeks is how far back generation looks when balancing rostered days off
+const offDayLookbackWeeks = 12
+
+// offDayStats counts the days an officer was rostered off while their team worked a shift
+type offDayStats struct {
+	Total     int
+	ByWeekday [7]int
+	Last      time.Time
+}
+
+// offDayHistory holds off-day counts by shift type and officer ID
+type offDayHistory map[models.ShiftType]map[uint]*offDayStats
+
+func (h offDayHistory) stats(shiftType models.ShiftType, officerID uint) *offDayStats {
+	byOfficer := h[shiftType]
+	if byOfficer == nil {
+		byOfficer = make(map[uint]*offDayStats)
+		h[shiftType] = byOfficer
+	}
+	s := byOfficer[officerID]
+	if s == nil {
+		s = &offDayStats{}
+		byOfficer[officerID] = s
+	}
+	return s
+}
+
+func (h offDayHistory) record(shiftType models.ShiftType, officerID uint, date time.Time) {
+	s := h.stats(shiftType, officerID)
+	s.Total++
+	s.ByWeekday[date.Weekday()]++
+	if date.After(s.Last) {
+		s.Last = date
+	}
+}
+
+// loadOffDayHistory counts off-duty days from from up to (not including) to that fell in weeks
+// the officer's team was on that shift. Resting weeks, and days before the officer joined their
+// current team, are not counted.
+func loadOffDayHistory(db *gorm.DB, from, to time.Time) (offDayHistory, error) {
+	var rotations []models.WeekRotation
+	if err := db.Where("week_start > ? AND week_start < ?", from.AddDate(0, 0, -7), to).Find(&rotations).Error; err != nil {
+		return nil, err
+	}
+	byWeek := make(map[string]models.WeekRotation, len(rotations))
+	for _, r := range rotations {
+		byWeek[r.WeekStart.Format("2006-01-02")] = r
+	}
+
+	var cycles []models.RotationCycle
+	if err := db.Preload("Steps").Find(&cycles).Error; err != nil {
+		return nil, err
+	}
+	cyclesByID := make(map[uint]models.RotationCycle, len(cycles))
+	for _, cycle := range cycles {
+		cyclesByID[cycle.ID] = cycle
+	}
+
+	var officers []models.Officer
+	if err := db.Find(&officers).Error; err != nil {
+		return nil, err
+	}
+	teams := make(map[uint]int, len(officers))
+	teamSince := make(map[uint]time.Time, len(officers))
+	for _, officer := range officers {
+		teams[officer.ID] = officer.Team
+		if officer.TeamSince != nil {
+			teamSince[officer.ID] = *officer.TeamSince
+		}
+	}
+
+	var shifts []models.Shift
+	if err := db.Where("status = ? AND date >= ? AND date < ?", models.StatusOffDuty, from, to).
+		Order("date ASC").Find(&shifts).Error; err != nil {
+		return nil, err
+	}
+
+	history := make(offDayHistory)
+	for _, shift := range shifts {
+		weekStart := shift.Date.AddDate(0, 0, -int(shift.Date.Weekday()))
+		rotation, ok := byWeek[weekStart.Format("2006-01-02")]
+		if !ok || shift.Date.Before(teamSince[shift.OfficerID]) {
+			continue
+		}
+		if teamAssignment(rotation, cyclesByID, teams[shift.OfficerID]) == string(shift.ShiftType) {
+			history.record(shift.ShiftType, shift.OfficerID, shift.Date)
+		}
+	}
+	return history, nil
+}
+
+// teamAssignment returns what a team worked in a week: its step in the cycle the week was generated
+// from, or for weeks without one the recorded day and night teams
+func teamAssignment(rotation models.WeekRotation, cycles map[uint]models.RotationCycle, team int) string {
+	if rotation.CycleID != nil {
+		if cycle, ok := cycles[*rotation.CycleID]; ok {
+			return cycle.Assignments(rotation.CycleWeek)[team]
+		}
+	}
+	switch team {
+	case rotation.DayShiftTeam:
+		return string(models.ShiftDay)
+	case rotation.NightShiftTeam:
+		return string(models.ShiftNight)
+	}
+	return models.CycleRest
+}
+
+// pickOffDuty chooses count officers to roster off on a date: fewest days off overall first, then fewest
+// on this weekday, then longest since their last day off, then ID order
+func pickOffDuty(history offDayHistory, shiftType models.ShiftType, officers []models.Officer, count int, date time.Time) map[uint]bool {
+	candidates := append([]models.Officer(nil), officers...)
+	weekday := date.Weekday()
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a := history.stats(shiftType, candidates[i].ID)
+		b := history.stats(shiftType, candidates[j].ID)
+		if a.Total != b.Total {
+			return a.Total < b.Total
+		}
+		if a.ByWeekday[weekday] != b.ByWeekday[weekday] {
+			return a.ByWeekday[weekday] < b.ByWeekday[weekday]
+		}
+		if !a.Last.Equal(b.Last) {
+			return a.Last.Before(b.Last)
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+
+	off := make(map[uint]bool, count)
+	for i := 0; i < count && i < len(candidates); i++ {
+		off[candidates[i].ID] = true
+	}
+	return off
+}
+
+// OfficerOffDays represents one officer's rostered days off over a period
+type OfficerOffDays struct {
+	OfficerID  uint           `json:"officer_id"`
+	Name       string         `json:"name"`
+	Team       int            `json:"team"`
+	Total      int            `json:"total"`
+	DayShift   int            `json:"day_shift"`   // Days off in day-shift weeks
+	NightShift int            `json:"night_shift"` // Days off in night-shift weeks
+	ByShift    map[string]int `json:"by_shift"`    // Days off by shift definition code
+	ByWeekday  map[string]int `json:"by_weekday"`
+}
+
+// GetOffDayDistribution godoc
+// @Summary Get days-off distribution
+// @Description Get how many days each officer was rostered off, by weekday, during weeks their team worked.
+// @Description Defaults to the 12 weeks before the current week.
+// @Tags rota
+// @Produce json
+// @Param from query string false "First date (YYYY-MM-DD)"
+// @Param to query string false "Last date, inclusive (YYYY-MM-DD)"
+// @Success 200 {array} OfficerOffDays
+// @Failure 400 {object} map[string]string
+// @Router /rota/off-days [get]
+func GetOffDayDistribution(c *gin.Context) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	to := today.AddDate(0, 0, -int(today.Weekday())-1)
+	from := to.AddDate(0, 0, 1-7*offDayLookbackWeeks)
+
+	var err error
+	if s := c.Query("from"); s != "" {
+		if from, err = time.Parse("2006-01-02", s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, use YYYY-MM-DD"})
+			return
+		}
+	}
+	if s := c.Query("to"); s != "" {
+		if to, err = time.Parse("2006-01-02", s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, use YYYY-MM-DD"})
+			return
+		}
+	}
+	if to.Before(from) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+
+	history, err := loadOffDayHistory(database.DB, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shifts"})
+		return
+	}
+
+	var officers []models.Officer
+	database.DB.Where("role = ?", models.RoleRegular).Order("team ASC, name ASC").Find(&officers)
+
+	result := make([]OfficerOffDays, 0, len(officers))
+	for _, officer := range officers {
+		entry := OfficerOffDays{
+			OfficerID: officer.ID,
+			Name:      officer.Name,
+			Team:      officer.Team,
+			ByShift:   make(map[string]int),
+			ByWeekday: make(map[string]int, 7),
+		}
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			entry.ByWeekday[d.String()] = 0
+		}
+		for shiftType, byOfficer := range history {
+			stats, ok := byOfficer[officer.ID]
+			if !ok {
+				continue
+			}
+			entry.Total += stats.Total
+			entry.ByShift[string(shiftType)] = stats.Total
+			for d := time.Sunday; d <= time.Saturday; d++ {
+				entry.ByWeekday[d.String()] += stats.ByWeekday[d]
+			}
+		}
+		entry.DayShift = entry.ByShift[string(models.ShiftDay)]
+		entry.NightShift = entry.ByShift[string(models.ShiftNight)]
+
+		result = append(result, entry)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,39 +1,70 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"securityrota-api/database"
 	"securityrota-api/models"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // CreateOfficerInput represents the input for creating an officer
 type CreateOfficerInput struct {
-	Name string             `json:"name" binding:"required"`
-	Role models.OfficerRole `json:"role" binding:"required"`
-	Team int                `json:"team" binding:"required,min=1,max=2"`
+	Name            string               `json:"name" binding:"required"`
+	Role            models.OfficerRole   `json:"role" binding:"required,oneof=sergeant regular"`
+	Team            int                  `json:"team" binding:"required,min=1"`
+	BadgeNo         string               `json:"badge_no"`
+	Rank            string               `json:"rank"`
+	ActingSergeant  bool                 `json:"acting_sergeant"` // Can supervise shifts when no sergeant is on duty
+	Gender          models.OfficerGender `json:"gender" binding:"omitempty,oneof=female male"`
+	Phone           string               `json:"phone"`
+	Email           string               `json:"email" binding:"omitempty,email"`
+	Status          models.OfficerStatus `json:"status" binding:"omitempty,oneof=active suspended resigned"` // Defaults to active
+	StartDate       string               `json:"start_date"`                                                 // YYYY-MM-DD, optional
+	EndDate         string               `json:"end_date"`                                                   // YYYY-MM-DD, optional
+	ContractedHours float64              `json:"contracted_hours" binding:"omitempty,min=0,max=168"`         // Weekly hours, defaults to 48
 }
 
 // UpdateOfficerInput represents the input for updating an officer
 type UpdateOfficerInput struct {
-	Name string             `json:"name"`
-	Role models.OfficerRole `json:"role"`
-	Team int                `json:"team"`
+	Name            string               `json:"name"`
+	Role            models.OfficerRole   `json:"role" binding:"omitempty,oneof=sergeant regular"`
+	Team            int                  `json:"team"`
+	BadgeNo         *string              `json:"badge_no"` // Empty string clears it
+	Rank            *string              `json:"rank"`
+	ActingSergeant  *bool                `json:"acting_sergeant"`
+	Gender          *string              `json:"gender" binding:"omitempty,oneof=female male"`
+	Phone           *string              `json:"phone"`
+	Email           *string              `json:"email" binding:"omitempty,email"`
+	Status          models.OfficerStatus `json:"status" binding:"omitempty,oneof=active suspended resigned"`
+	StartDate       *string              `json:"start_date"` // YYYY-MM-DD; empty string clears it
+	EndDate         *string              `json:"end_date"`   // YYYY-MM-DD; empty string clears it
+	ContractedHours *float64             `json:"contracted_hours" binding:"omitempty,min=0,max=168"`
 }
 
 // GetOfficers godoc
 // @Summary Get all officers
-// @Description Get list of all security officers
+// @Description Get list of all security officers, optionally filtered by employment status
 // @Tags officers
 // @Produce json
+// @Param status query string false "active, suspended or resigned"
 // @Success 200 {array} models.Officer
 // @Router /officers [get]
 func GetOfficers(c *gin.Context) {
+	query := database.DB.Preload("Qualifications")
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
 	var officers []models.Officer
-	database.DB.Find(&officers)
+	query.Find(&officers)
 	c.JSON(http.StatusOK, officers)
 }
 
@@ -49,7 +80,7 @@ func GetOfficers(c *gin.Context) {
 func GetOfficer(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
 	var officer models.Officer
-	if err := database.DB.First(&officer, id).Error; err != nil {
+	if err := database.DB.Preload("Qualifications").First(&officer, id).Error; err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Officer not found"})
 		return
 	}
@@ -73,10 +104,49 @@ func CreateOfficer(c *gin.Context) {
 		return
 	}
 
+	if err := validateTeam(input.Team); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	startDate, err := parseOptionalDate(input.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date, use YYYY-MM-DD"})
+		return
+	}
+	endDate, err := parseOptionalDate(input.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date, use YYYY-MM-DD"})
+		return
+	}
+	if err := validateEmployment(startDate, endDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := input.Status
+	if status == "" {
+		status = models.OfficerActive
+	}
+	contractedHours := input.ContractedHours
+	if contractedHours == 0 {
+		contractedHours = models.DefaultContractedHours
+	}
+
 	officer := models.Officer{
-		Name: input.Name,
-		Role: input.Role,
-		Team: input.Team,
+		Name:            input.Name,
+		Role:            input.Role,
+		Team:            input.Team,
+		BadgeNo:         badgeNo(input.BadgeNo),
+		Rank:            input.Rank,
+		ActingSergeant:  input.ActingSergeant,
+		Gender:          input.Gender,
+		Phone:           input.Phone,
+		Email:           input.Email,
+		Status:          status,
+		StartDate:       startDate,
+		EndDate:         endDate,
+		ContractedHours: contractedHours,
 	}
 
 	if err := database.DB.Create(&officer).Error; err != nil {
@@ -89,15 +159,17 @@ func CreateOfficer(c *gin.Context) {
 
 // UpdateOfficer godoc
 // @Summary Update an officer
-// @Description Update an existing security officer
+// @Description Update an existing security officer. Shifts from today on that fall on dates the officer can no longer
+// @Description be rostered (suspended, resigned or past their end_date) are removed and listed in the response.
 // @Tags officers
 // @Accept json
 // @Produce json
 // @Param id path int true "Officer ID"
 // @Param input body UpdateOfficerInput true "Officer data"
-// @Success 200 {object} models.Officer
+// @Success 200 {object} UpdateOfficerResponse
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
 // @Router /officers/{id} [put]
 func UpdateOfficer(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
@@ -113,17 +185,120 @@ func UpdateOfficer(c *gin.Context) {
 		return
 	}
 
-	database.DB.Model(&officer).Updates(input)
-	c.JSON(http.StatusOK, officer)
+	if input.Team != 0 {
+		if err := validateTeam(input.Team); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	updates := map[string]interface{}{}
+	if input.Name != "" {
+		updates["name"] = input.Name
+	}
+	if input.Role != "" {
+		updates["role"] = input.Role
+	}
+	if input.Team != 0 && input.Team != officer.Team {
+		// Days off balancing only counts history from the officer's current team
+		now := time.Now()
+		updates["team"] = input.Team
+		updates["team_since"] = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	if input.Status != "" {
+		updates["status"] = input.Status
+	}
+	if input.BadgeNo != nil {
+		updates["badge_no"] = badgeNo(*input.BadgeNo)
+	}
+	if input.Rank != nil {
+		updates["rank"] = *input.Rank
+	}
+	if input.ActingSergeant != nil {
+		updates["acting_sergeant"] = *input.ActingSergeant
+	}
+	if input.Gender != nil {
+		updates["gender"] = *input.Gender
+	}
+	if input.Phone != nil {
+		updates["phone"] = *input.Phone
+	}
+	if input.Email != nil {
+		updates["email"] = *input.Email
+	}
+	if input.ContractedHours != nil {
+		updates["contracted_hours"] = *input.ContractedHours
+	}
+
+	startDate, endDate := officer.StartDate, officer.EndDate
+	if input.StartDate != nil {
+		date, err := parseOptionalDate(*input.StartDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_date, use YYYY-MM-DD"})
+			return
+		}
+		startDate = date
+		updates["start_date"] = date
+	}
+	if input.EndDate != nil {
+		date, err := parseOptionalDate(*input.EndDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_date, use YYYY-MM-DD"})
+			return
+		}
+		endDate = date
+		updates["end_date"] = date
+	}
+	if err := validateEmployment(startDate, endDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Shifts on dates the officer can no longer be rostered are removed with the change
+	var released releasedShifts
+	var conflicts []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if len(updates) > 0 {
+			if err := tx.Model(&officer).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.First(&officer, officer.ID).Error; err != nil {
+			return err
+		}
+
+		var err error
+		released, conflicts, err = releaseOfficerShifts(tx, officer.ID, officer.ActiveOn)
+		if err == nil && len(conflicts) > 0 {
+			return errShiftConflict
+		}
+		return err
+	})
+	if err == errShiftConflict {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  "Officer has hand-edited on-duty shifts after the change; reassign or delete them first",
+			"shifts": conflicts,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, UpdateOfficerResponse{Officer: officer, releasedShifts: released})
 }
 
 // DeleteOfficer godoc
 // @Summary Delete an officer
-// @Description Delete a security officer
+// @Description Soft-delete a security officer. They are no longer rostered or listed, but historic shifts still show them.
+// @Description Their shifts from today on are removed; hand-edited on-duty shifts must be reassigned first.
 // @Tags officers
+// @Produce json
 // @Param id path int true "Officer ID"
-// @Success 204
+// @Success 200 {object} map[string]interface{}
 // @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
 // @Router /officers/{id} [delete]
 func DeleteOfficer(c *gin.Context) {
 	id, _ := strconv.Atoi(c.Param("id"))
@@ -133,6 +308,121 @@ func DeleteOfficer(c *gin.Context) {
 		return
 	}
 
-	database.DB.Delete(&officer)
-	c.JSON(http.StatusNoContent, nil)
+	var released releasedShifts
+	var conflicts []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		released, conflicts, err = releaseOfficerShifts(tx, officer.ID, func(time.Time) bool { return false })
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return errShiftConflict
+		}
+		return tx.Delete(&officer).Error
+	})
+	if err == errShiftConflict {
+		c.JSON(http.StatusConflict, gin.H{
+			"error":  "Officer has hand-edited on-duty shifts from today on; reassign or delete them first",
+			"shifts": conflicts,
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete officer"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Officer deleted",
+		"removed_shifts": released.RemovedShifts,
+		"affected_weeks": released.AffectedWeeks,
+	})
+}
+
+// UpdateOfficerResponse is the updated officer with any shifts removed because of the change
+type UpdateOfficerResponse struct {
+	models.Officer
+	releasedShifts
+}
+
+// releasedShifts counts the shifts removed from an officer who left or stopped being rostered
+type releasedShifts struct {
+	RemovedShifts int      `json:"removed_shifts,omitempty"`
+	AffectedWeeks []string `json:"affected_weeks,omitempty"` // Sundays of the weeks that lost shifts
+}
+
+// errShiftConflict rolls back an officer change that would leave hand-edited shifts behind
+var errShiftConflict = errors.New("officer has hand-edited on-duty shifts")
+
+// releaseOfficerShifts removes an officer's shifts from today on, on dates rostered reports false for.
+// Hand-edited on-duty shifts are not removed but returned as conflicts for the caller to refuse the change.
+func releaseOfficerShifts(tx *gorm.DB, officerID uint, rostered func(time.Time) bool) (releasedShifts, []string, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var shifts []models.Shift
+	if err := tx.Where("officer_id = ? AND date >= ?", officerID, today).Order("date ASC").Find(&shifts).Error; err != nil {
+		return releasedShifts{}, nil, err
+	}
+
+	var released releasedShifts
+	var removed []models.Shift
+	var conflicts []string
+	weeks := make(map[string]bool)
+	for _, shift := range shifts {
+		if rostered(shift.Date) {
+			continue
+		}
+		if shift.Manual && shift.Status == models.StatusOnDuty {
+			conflicts = append(conflicts, fmt.Sprintf("%s %s shift (shift %d)", shift.Date.Format("2006-01-02"), shift.ShiftType, shift.ID))
+			continue
+		}
+		removed = append(removed, shift)
+		week := shift.Date.AddDate(0, 0, -int(shift.Date.Weekday())).Format("2006-01-02")
+		if !weeks[week] {
+			weeks[week] = true
+			released.AffectedWeeks = append(released.AffectedWeeks, week)
+		}
+	}
+	if len(conflicts) > 0 || len(removed) == 0 {
+		return released, conflicts, nil
+	}
+
+	released.RemovedShifts = len(removed)
+	return released, nil, deleteShifts(tx, removed)
+}
+
+// badgeNo stores an empty badge number as NULL so the unique index only applies to real numbers
+func badgeNo(s string) *string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// parseOptionalDate parses a YYYY-MM-DD date, returning nil for an empty string
+func parseOptionalDate(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	date, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// validateEmployment checks that an officer's end date is not before their start date
+func validateEmployment(startDate, endDate *time.Time) error {
+	if startDate != nil && endDate != nil && endDate.Before(*startDate) {
+		return errors.New("end_date must not be before start_date")
+	}
+	return nil
+}
+
+// withFormerOfficers preloads officers including soft-deleted ones, so historic shifts keep their names
+func withFormerOfficers(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
 }
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QualificationInput represents a certification held by an officer
+type QualificationInput struct {
+	Name      string `json:"name" binding:"required"`
+	IssuedOn  string `json:"issued_on"`  // YYYY-MM-DD, optional
+	ExpiresOn string `json:"expires_on"` // YYYY-MM-DD, empty for no expiry
+}
+
+// GetQualifications godoc
+// @Summary Get an officer's qualifications
+// @Description Get the qualifications and certifications held by an officer
+// @Tags officers
+// @Produce json
+// @Param id path int true "Officer ID"
+// @Success 200 {array} models.Qualification
+// @Failure 404 {object} map[string]string
+// @Router /officers/{id}/qualifications [get]
+func GetQualifications(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var officer models.Officer
+	if err := database.DB.First(&officer, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Officer not found"})
+		return
+	}
+
+	var qualifications []models.Qualification
+	database.DB.Where("officer_id = ?", officer.ID).Order("name ASC").Find(&qualifications)
+	c.JSON(http.StatusOK, qualifications)
+}
+
+// CreateQualification godoc
+// @Summary Add a qualification
+// @Description Record a qualification or certification for an officer
+// @Tags officers
+// @Accept json
+// @Produce json
+// @Param id path int true "Officer ID"
+// @Param input body QualificationInput true "Qualification"
+// @Success 201 {object} models.Qualification
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /officers/{id}/qualifications [post]
+func CreateQualification(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var officer models.Officer
+	if err := database.DB.First(&officer, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Officer not found"})
+		return
+	}
+
+	var input QualificationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	qualification := models.Qualification{OfficerID: officer.ID}
+	if err := input.apply(&qualification); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Create(&qualification).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create qualification"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, qualification)
+}
+
+// UpdateQualification godoc
+// @Summary Update a qualification
+// @Description Replace a qualification's name and dates, e.g. after renewal
+// @Tags officers
+// @Accept json
+// @Produce json
+// @Param id path int true "Officer ID"
+// @Param qid path int true "Qualification ID"
+// @Param input body QualificationInput true "Qualification"
+// @Success 200 {object} models.Qualification
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /officers/{id}/qualifications/{qid} [put]
+func UpdateQualification(c *gin.Context) {
+	qualification, ok := findQualification(c)
+	if !ok {
+		return
+	}
+
+	var input QualificationInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := input.apply(&qualification); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Model(&qualification).Updates(map[string]interface{}{
+		"name":       qualification.Name,
+		"issued_on":  qualification.IssuedOn,
+		"expires_on": qualification.ExpiresOn,
+	}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update qualification"})
+		return
+	}
+
+	c.JSON(http.StatusOK, qualification)
+}
+
+// DeleteQualification godoc
+// @Summary Delete a qualification
+// @Description Remove a qualification from an officer
+// @Tags officers
+// @Param id path int true "Officer ID"
+// @Param qid path int true "Qualification ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /officers/{id}/qualifications/{qid} [delete]
+func DeleteQualification(c *gin.Context) {
+	qualification, ok := findQualification(c)
+	if !ok {
+		return
+	}
+
+	database.DB.Delete(&qualification)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetExpiringQualifications godoc
+// @Summary Get expiring qualifications
+// @Description Get qualifications that have expired or expire within the given number of days
+// @Tags officers
+// @Produce json
+// @Param within_days query int false "Days ahead to look (default 30)"
+// @Success 200 {array} models.Qualification
+// @Router /qualifications/expiring [get]
+func GetExpiringQualifications(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("within_days", "30"))
+	if err != nil || days < 0 {
+		days = 30
+	}
+
+	var qualifications []models.Qualification
+	database.DB.Preload("Officer", withFormerOfficers).
+		Joins("JOIN officers ON officers.id = qualifications.officer_id AND officers.deleted_at IS NULL").
+		Where("qualifications.expires_on <= ?", time.Now().AddDate(0, 0, days)).
+		Order("qualifications.expires_on ASC").
+		Find(&qualifications)
+	c.JSON(http.StatusOK, qualifications)
+}
+
+// findQualification loads the qualification in the path, checking it belongs to the officer in the path
+func findQualification(c *gin.Context) (models.Qualification, bool) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	qid, _ := strconv.Atoi(c.Param("qid"))
+
+	var qualification models.Qualification
+	if err := database.DB.Where("officer_id = ?", id).First(&qualification, qid).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Qualification not found"})
+		return qualification, false
+	}
+	return qualification, true
+}
+
+// apply copies the input onto a qualification, parsing its dates
+func (input QualificationInput) apply(q *models.Qualification) error {
+	issuedOn, err := parseOptionalDate(input.IssuedOn)
+	if err != nil {
+		return errors.New("Invalid issued_on, use YYYY-MM-DD")
+	}
+	expiresOn, err := parseOptionalDate(input.ExpiresOn)
+	if err != nil {
+		return errors.New("Invalid expires_on, use YYYY-MM-DD")
+	}
+	if issuedOn != nil && expiresOn != nil && expiresOn.Before(*issuedOn) {
+		return errors.New("expires_on must not be before issued_on")
+	}
+
+	q.Name = input.Name
+	q.IssuedOn = issuedOn
+	q.ExpiresOn = expiresOn
+	return nil
+}
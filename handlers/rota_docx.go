@@ -11,7 +11,10 @@ import (
 	"securityrota-api/models"
 
 	"github.com/gin-gonic/gin"
+	"github.com/unidoc/unioffice/color"
 	"github.com/unidoc/unioffice/document"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/wml"
 )
 
 // GetWeekRotaDOCX godoc
@@ -50,45 +53,7 @@ func GetWeekRotaDOCX(c *gin.Context) {
 	}
 
 	weekEnd := weekStart.AddDate(0, 0, 6)
-
-	// Get all shifts for the week
-	var shifts []models.Shift
-	database.DB.Preload("Officer").
-		Where("date >= ? AND date <= ?", weekStart, weekEnd).
-		Order("date ASC, shift_type ASC, officer_id ASC").
-		Find(&shifts)
-
-	// Organize shifts by day
-	type dayData struct {
-		date       time.Time
-		dayShift   []string
-		nightShift []string
-		leave      []string
-	}
-	days := make([]dayData, 7)
-	for i := 0; i < 7; i++ {
-		days[i] = dayData{date: weekStart.AddDate(0, 0, i)}
-	}
-
-	for _, shift := range shifts {
-		dayIndex := int(shift.Date.Sub(weekStart).Hours() / 24)
-		if dayIndex < 0 || dayIndex > 6 {
-			continue
-		}
-
-		name := shift.Officer.Name
-		name = strings.TrimPrefix(name, "Officer ")
-		name = strings.TrimPrefix(name, "Sgt. ")
-		name = strings.ToUpper(name)
-
-		if shift.Status == models.StatusOffDuty {
-			days[dayIndex].leave = append(days[dayIndex].leave, name)
-		} else if shift.ShiftType == models.ShiftDay {
-			days[dayIndex].dayShift = append(days[dayIndex].dayShift, name)
-		} else {
-			days[dayIndex].nightShift = append(days[dayIndex].nightShift, name)
-		}
-	}
+	days, rows := loadRotaGrid(weekStart)
 
 	// Create a new document
 	doc := document.New()
@@ -106,12 +71,8 @@ func GetWeekRotaDOCX(c *gin.Context) {
 	run.Properties().SetSize(22)
 
 	para = doc.AddParagraph()
-	nightShiftTeam := 1
-	if rotation.DayShiftTeam == 1 {
-		nightShiftTeam = 2
-	}
 	run = para.AddRun()
-	run.AddText(fmt.Sprintf("Day Shift: Team %d | Night Shift: Team %d", rotation.DayShiftTeam, nightShiftTeam))
+	run.AddText(fmt.Sprintf("Day Shift: Team %d | Night Shift: Team %d", rotation.DayShiftTeam, rotation.NightShiftTeam))
 	run.Properties().SetSize(18)
 
 	doc.AddParagraph()
@@ -136,63 +97,180 @@ func GetWeekRotaDOCX(c *gin.Context) {
 		run.Properties().SetBold(true)
 		para.AddRun().AddBreak()
 		para.AddRun().AddText(days[i].date.Format("02/01/06"))
+		if days[i].holiday != "" {
+			cell.Properties().SetShading(wml.ST_ShdSolid, color.FromHex("#FFCCCC"), color.Auto)
+			para.AddRun().AddBreak()
+			run = para.AddRun()
+			run.AddText(strings.ToUpper(days[i].holiday))
+			run.Properties().SetItalic(true)
+		}
 	}
 
-	// DAY SHIFT row
-	row = table.AddRow()
-	cell = row.AddCell()
-	para = cell.AddParagraph()
-	run = para.AddRun()
-	run.AddText("DAY SHIFT")
-	run.Properties().SetBold(true)
-
-	for _, d := range days {
+	for _, r := range rows {
+		row = table.AddRow()
 		cell = row.AddCell()
-		for i, name := range d.dayShift {
-			if i > 0 {
-				cell.AddParagraph()
+		para = cell.AddParagraph()
+		run = para.AddRun()
+		run.AddText(r.label)
+		run.Properties().SetBold(true)
+		if r.times != "" {
+			para.AddRun().AddBreak()
+			para.AddRun().AddText(r.times)
+		}
+		if r.colour != "" {
+			cell.Properties().SetShading(wml.ST_ShdSolid, color.FromHex(r.colour), color.Auto)
+		}
+
+		for _, d := range days {
+			cell = row.AddCell()
+			for i, name := range r.names(d) {
+				if i > 0 {
+					cell.AddParagraph()
+				}
+				para = cell.AddParagraph()
+				para.AddRun().AddText(name)
 			}
-			para = cell.AddParagraph()
-			para.AddRun().AddText(name)
 		}
 	}
 
-	// NIGHT SHIFT row
-	row = table.AddRow()
-	cell = row.AddCell()
-	para = cell.AddParagraph()
-	run = para.AddRun()
-	run.AddText("NIGHT SHIFT")
+	// Generate document bytes
+	var buf bytes.Buffer
+	if err := doc.Save(&buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate DOCX"})
+		return
+	}
+
+	// Output
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.wordprocessingml.document")
+	filename := fmt.Sprintf("rota_%s.docx", weekStartStr)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", buf.Bytes())
+}
+
+// GetMonthRotaDOCX godoc
+// @Summary Download monthly rota as DOCX
+// @Description Generate and download a DOCX of a calendar month's rota: one row per officer with a shift code
+// @Description for each day and their shift, day-off, leave and hour totals
+// @Tags rota
+// @Produce application/vnd.openxmlformats-officedocument.wordprocessingml.document
+// @Param month query string true "Month (YYYY-MM)"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /rota/month/docx [get]
+func GetMonthRotaDOCX(c *gin.Context) {
+	month, ok := monthQuery(c)
+	if !ok {
+		return
+	}
+
+	grid, found := loadMonthGrid(month)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No rota found for this month"})
+		return
+	}
+
+	// Landscape A4 with narrow margins to fit a column per day
+	doc := document.New()
+	section := doc.BodySection()
+	section.SetPageSizeAndOrientation(297*measurement.Millimeter, 210*measurement.Millimeter, wml.ST_PageOrientationLandscape)
+	margin := measurement.Distance(10 * measurement.Millimeter)
+	section.SetPageMargins(margin, margin, margin, margin, margin, margin, 0)
+
+	// Title
+	para := doc.AddParagraph()
+	run := para.AddRun()
+	run.AddText("Security Officer Duty Rota")
 	run.Properties().SetBold(true)
+	run.Properties().SetSize(28)
 
-	for _, d := range days {
-		cell = row.AddCell()
-		for i, name := range d.nightShift {
+	para = doc.AddParagraph()
+	run = para.AddRun()
+	run.AddText(month.Format("January 2006"))
+	run.Properties().SetSize(22)
+
+	table := doc.AddTable()
+	table.Properties().SetLayout(wml.ST_TblLayoutTypeFixed)
+	table.Properties().Borders().SetAll(wml.ST_BorderSingle, color.Auto, measurement.Zero)
+
+	// addCell adds a cell of the given width with small centred text
+	addCell := func(row document.Row, width measurement.Distance, text string, bold bool, fill string) {
+		cell := row.AddCell()
+		cell.Properties().SetWidth(width)
+		if fill != "" {
+			cell.Properties().SetShading(wml.ST_ShdSolid, color.FromHex(fill), color.Auto)
+		}
+		para := cell.AddParagraph()
+		para.Properties().SetAlignment(wml.ST_JcCenter)
+		for i, line := range strings.Split(text, "\n") {
 			if i > 0 {
-				cell.AddParagraph()
+				para.AddRun().AddBreak()
 			}
-			para = cell.AddParagraph()
-			para.AddRun().AddText(name)
+			run := para.AddRun()
+			run.AddText(line)
+			run.Properties().SetSize(7)
+			run.Properties().SetBold(bold)
 		}
 	}
 
-	// DAY-OFF row
-	row = table.AddRow()
-	cell = row.AddCell()
-	para = cell.AddParagraph()
-	run = para.AddRun()
-	run.AddText("DAY-OFF")
-	run.Properties().SetBold(true)
+	// Column widths: the days share what the name and total columns leave of the 277mm line
+	nameWidth := measurement.Distance(36 * measurement.Millimeter)
+	totalWidth := measurement.Distance(8 * measurement.Millimeter)
+	totals := len(grid.definitions) + 3
+	dayWidth := (277*measurement.Millimeter - nameWidth - totalWidth*measurement.Distance(totals)) / measurement.Distance(len(grid.days))
 
-	for _, d := range days {
-		cell = row.AddCell()
-		for i, name := range d.leave {
-			if i > 0 {
-				cell.AddParagraph()
-			}
-			para = cell.AddParagraph()
-			para.AddRun().AddText(name)
+	// Header row, repeated at the top of each page
+	row := table.AddRow()
+	row.Properties().SetTblHeader(true)
+	addCell(row, nameWidth, "OFFICER", true, "#C0C0C0")
+	for _, d := range grid.days {
+		fill := "#F2F2F2"
+		switch {
+		case grid.holidays[d.Format("2006-01-02")] != "":
+			fill = "#FFCCCC"
+		case d.Weekday() == time.Saturday || d.Weekday() == time.Sunday:
+			fill = "#D9D9D9"
+		}
+		addCell(row, dayWidth, fmt.Sprintf("%d\n%s", d.Day(), d.Weekday().String()[:1]), true, fill)
+	}
+	for _, d := range grid.definitions {
+		addCell(row, totalWidth, grid.abbreviations[d.Code], true, "#C0C0C0")
+	}
+	addCell(row, totalWidth, "OFF", true, "#C0C0C0")
+	addCell(row, totalWidth, "LV", true, "#C0C0C0")
+	addCell(row, totalWidth, "HRS", true, "#C0C0C0")
+
+	for _, officer := range grid.officers {
+		row = table.AddRow()
+		row.Properties().SetCantSplit(true)
+
+		cell := row.AddCell()
+		cell.Properties().SetWidth(nameWidth)
+		run = cell.AddParagraph().AddRun()
+		run.AddText(officer.name)
+		run.Properties().SetSize(7)
+
+		for _, day := range officer.cells {
+			addCell(row, dayWidth, day.code, false, day.colour)
 		}
+		for _, d := range grid.definitions {
+			addCell(row, totalWidth, fmt.Sprint(officer.shifts[d.Code]), true, "")
+		}
+		addCell(row, totalWidth, fmt.Sprint(officer.off), true, "")
+		addCell(row, totalWidth, fmt.Sprint(officer.leave), true, "")
+		addCell(row, totalWidth, fmt.Sprintf("%g", officer.hours), true, "")
+	}
+
+	// Legend
+	para = doc.AddParagraph()
+	run = para.AddRun()
+	run.AddText(grid.legend())
+	run.Properties().SetSize(8)
+	if holidays := grid.holidayList(); holidays != "" {
+		para = doc.AddParagraph()
+		run = para.AddRun()
+		run.AddText("Public holidays: " + holidays)
+		run.Properties().SetSize(8)
 	}
 
 	// Generate document bytes
@@ -204,7 +282,7 @@ func GetWeekRotaDOCX(c *gin.Context) {
 
 	// Output
 	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.wordprocessingml.document")
-	filename := fmt.Sprintf("rota_%s.docx", weekStartStr)
+	filename := fmt.Sprintf("rota_%s.docx", month.Format("2006-01"))
 	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
 	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", buf.Bytes())
 }
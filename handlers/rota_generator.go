@@ -0,0 +1,751 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// generationError is a rota generation failure with the HTTP status and code reported to the client
+type generationError struct {
+	Status     int
+	Code       string
+	Message    string
+	Details    string
+	Shortfalls []CoverageShortfall
+}
+
+func (e *generationError) Error() string {
+	if e.Details == "" {
+		return e.Message
+	}
+	return e.Message + ": " + e.Details
+}
+
+// errRotaExists is returned when generating a week that already has a rota
+var errRotaExists = &generationError{
+	Status:  http.StatusBadRequest,
+	Code:    "rota_exists",
+	Message: "Rota already exists for this week. Use mode=regenerate or DELETE /shifts/week first.",
+}
+
+// dbError wraps a database failure during generation
+func dbError(step string, err error) *generationError {
+	return &generationError{
+		Status:  http.StatusInternalServerError,
+		Code:    "database_error",
+		Message: "Failed to " + step,
+		Details: err.Error(),
+	}
+}
+
+// coverageError refuses a week whose rota would not meet the coverage rules
+func coverageError(weekStart time.Time, shortfalls []CoverageShortfall) *generationError {
+	return &generationError{
+		Status:     http.StatusUnprocessableEntity,
+		Code:       "coverage_shortfall",
+		Message:    "Rota does not meet the coverage rules",
+		Details:    fmt.Sprintf("week of %s has %d shortfalls", weekStart.Format("2006-01-02"), len(shortfalls)),
+		Shortfalls: shortfalls,
+	}
+}
+
+// generationErrorResponse turns a generateWeek error into a status and JSON body
+func generationErrorResponse(err error) (int, gin.H) {
+	var genErr *generationError
+	if !errors.As(err, &genErr) {
+		genErr = dbError("generate rota", err)
+	}
+	body := gin.H{"error": genErr.Message, "code": genErr.Code}
+	if genErr.Details != "" {
+		body["details"] = genErr.Details
+	}
+	if len(genErr.Shortfalls) > 0 {
+		body["shortfalls"] = genErr.Shortfalls
+	}
+	return genErr.Status, body
+}
+
+// generateOptions controls how generateWeek treats existing data
+type generateOptions struct {
+	DryRun     bool // Build the shifts without saving anything
+	Regenerate bool // Replace an existing week, keeping manual and leave shifts
+	Strict     bool // Refuse weeks that break a coverage rule instead of warning
+}
+
+// weekResult summarizes a generated (or previewed) week
+type weekResult struct {
+	WeekStart      string              `json:"week_start"`
+	DayShiftTeam   int                 `json:"day_shift_team"`
+	NightShiftTeam int                 `json:"night_shift_team"`
+	RestTeams      []int               `json:"rest_teams"`
+	CycleWeek      int                 `json:"cycle_week"`
+	RuleSet        string              `json:"rule_set"`
+	ShiftsCreated  int                 `json:"shifts_created"`
+	ShiftsKept     int                 `json:"shifts_kept"`
+	Shortfalls     []CoverageShortfall `json:"shortfalls"`       // Coverage rules the week does not meet
+	Shifts         []models.Shift      `json:"shifts,omitempty"` // Proposed shifts, dry run only
+}
+
+// generateWeek builds a week's rota from the active cycle and rule set and saves it unless DryRun is set.
+// All reads and writes run in one transaction, so a failure leaves the week as it was.
+func generateWeek(db *gorm.DB, weekStart time.Time, opts generateOptions) (*weekResult, error) {
+	var result *weekResult
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = generateWeekTx(tx, weekStart, opts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func generateWeekTx(tx *gorm.DB, weekStart time.Time, opts generateOptions) (*weekResult, error) {
+	var rotation models.WeekRotation
+	err := tx.Where("week_start = ?", weekStart).First(&rotation).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, dbError("load week rotation", err)
+	}
+	exists := err == nil
+	if exists && !opts.Regenerate {
+		return nil, errRotaExists
+	}
+
+	// Continue the rotation cycle from the latest earlier week; a regenerated week keeps its place
+	cycle, err := loadActiveCycle(tx)
+	if err != nil {
+		return nil, dbError("load rotation cycle", err)
+	}
+	cycleWeek, err := cycleWeekFor(tx, cycle, weekStart)
+	if err != nil {
+		return nil, dbError("load previous rotation", err)
+	}
+	if exists && rotation.CycleID != nil && *rotation.CycleID == cycle.ID {
+		cycleWeek = rotation.CycleWeek
+	}
+	assignments := cycle.Assignments(cycleWeek)
+
+	rotation.WeekStart = weekStart
+	rotation.DayShiftTeam = cycle.TeamOn(cycleWeek, string(models.ShiftDay))
+	rotation.NightShiftTeam = cycle.TeamOn(cycleWeek, string(models.ShiftNight))
+	rotation.CycleWeek = cycleWeek
+	rotation.CycleID = nil
+	if cycle.ID > 0 {
+		rotation.CycleID = &cycle.ID
+	}
+
+	// Build shifts from the active rotation rules
+	rules, err := loadActiveRuleSet(tx)
+	if err != nil {
+		return nil, dbError("load rule set", err)
+	}
+	shifts, err := buildWeekShifts(tx, weekStart, assignments, rules)
+	if err != nil {
+		return nil, dbError("build shifts", err)
+	}
+
+	// When regenerating, manual edits and leave overrides win over generated shifts
+	var kept, replaced []models.Shift
+	if exists {
+		var existing []models.Shift
+		if err := tx.Where("date >= ? AND date <= ?", weekStart, weekStart.AddDate(0, 0, 6)).Find(&existing).Error; err != nil {
+			return nil, dbError("load existing shifts", err)
+		}
+
+		keptSlots := make(map[string]bool)
+		for _, shift := range existing {
+			if shift.Manual || shift.Status == models.StatusOnLeave {
+				kept = append(kept, shift)
+				keptSlots[officerDateKey(shift.OfficerID, shift.Date)] = true
+			} else {
+				replaced = append(replaced, shift)
+			}
+		}
+
+		var fresh []models.Shift
+		for _, shift := range shifts {
+			if !keptSlots[officerDateKey(shift.OfficerID, shift.Date)] {
+				fresh = append(fresh, shift)
+			}
+		}
+		shifts = fresh
+	}
+
+	result := &weekResult{
+		WeekStart:      weekStart.Format("2006-01-02"),
+		DayShiftTeam:   rotation.DayShiftTeam,
+		NightShiftTeam: rotation.NightShiftTeam,
+		RestTeams:      restTeams(assignments),
+		CycleWeek:      cycleWeek,
+		RuleSet:        rules.Name,
+		ShiftsCreated:  len(shifts),
+		ShiftsKept:     len(kept),
+	}
+
+	// Check the week as it will be saved: kept shifts plus the new ones
+	week := append(append([]models.Shift(nil), kept...), shifts...)
+	if err := attachOfficers(tx, week); err != nil {
+		return nil, dbError("load officers", err)
+	}
+	result.Shortfalls = weekCoverage(rules, weekStart, week)
+	if opts.Strict && len(result.Shortfalls) > 0 {
+		return nil, coverageError(weekStart, result.Shortfalls)
+	}
+
+	if opts.DryRun {
+		result.Shifts = week[len(kept):]
+		return result, nil
+	}
+
+	if err := tx.Save(&rotation).Error; err != nil {
+		return nil, dbError("save week rotation", err)
+	}
+	if len(replaced) > 0 {
+		if err := deleteShifts(tx, replaced); err != nil {
+			return nil, dbError("remove replaced shifts", err)
+		}
+	}
+
+	// Batch insert shifts; the unique (officer, date, shift type) index rejects duplicates
+	if len(shifts) > 0 {
+		if err := tx.Create(&shifts).Error; err != nil {
+			return nil, dbError("save shifts", err)
+		}
+	}
+
+	return result, nil
+}
+
+// rangeWeekResult is one week's entry in a range generation summary
+type rangeWeekResult struct {
+	Status string `json:"status"` // generated or skipped
+	*weekResult
+}
+
+// generateRange generates every week from startWeek to endWeek inclusive, skipping weeks that already
+// have a rota. All weeks are generated in one transaction: a failure saves none of them.
+func generateRange(db *gorm.DB, startWeek, endWeek time.Time, opts generateOptions) (weeks []rangeWeekResult, generated, skipped int, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for week := startWeek; !week.After(endWeek); week = week.AddDate(0, 0, 7) {
+			result, err := generateWeek(tx, week, opts)
+			if err == errRotaExists {
+				skipped++
+				weeks = append(weeks, rangeWeekResult{
+					Status:     "skipped",
+					weekResult: &weekResult{WeekStart: week.Format("2006-01-02")},
+				})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			generated++
+			weeks = append(weeks, rangeWeekResult{Status: "generated", weekResult: result})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return weeks, generated, skipped, nil
+}
+
+// deleteShifts removes shifts along with swap requests that refer to them
+func deleteShifts(db *gorm.DB, shifts []models.Shift) error {
+	ids := make([]uint, len(shifts))
+	for i, shift := range shifts {
+		ids[i] = shift.ID
+	}
+	if err := db.Where("requester_shift_id IN ? OR target_shift_id IN ?", ids, ids).Delete(&models.ShiftSwap{}).Error; err != nil {
+		return err
+	}
+	return db.Where("id IN ?", ids).Delete(&models.Shift{}).Error
+}
+
+// attachOfficers fills in the Officer of each shift for display, including officers since deleted
+func attachOfficers(db *gorm.DB, shifts []models.Shift) error {
+	var officers []models.Officer
+	if err := db.Unscoped().Find(&officers).Error; err != nil {
+		return err
+	}
+
+	byID := make(map[uint]models.Officer, len(officers))
+	for _, officer := range officers {
+		byID[officer.ID] = officer
+	}
+	for i := range shifts {
+		shifts[i].Officer = byID[shifts[i].OfficerID]
+	}
+	return nil
+}
+
+func officerDateKey(officerID uint, date time.Time) string {
+	return fmt.Sprintf("%d/%s", officerID, date.Format("2006-01-02"))
+}
+
+// loadActiveRuleSet returns the active rotation rules, falling back to the built-in defaults
+func loadActiveRuleSet(db *gorm.DB) (models.RuleSet, error) {
+	var rules models.RuleSet
+	err := db.Preload("Templates", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC, id ASC")
+	}).Preload("Staffing").Preload("Coverage").
+		Where("active = ?", true).
+		First(&rules).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultRuleSet(), nil
+	}
+	return rules, err
+}
+
+// pinnedOfficer is an officer held to a fixed weekly template instead of the team rotation
+type pinnedOfficer struct {
+	officer  models.Officer
+	template models.ShiftTemplate
+}
+
+// buildWeekShifts produces the shifts for a week from the rule set and each team's assignment
+// (day, night or rest) for the week
+func buildWeekShifts(db *gorm.DB, weekStart time.Time, assignments map[int]string, rules models.RuleSet) ([]models.Shift, error) {
+	var all []models.Officer
+	if err := db.Order("id ASC").Find(&all).Error; err != nil {
+		return nil, err
+	}
+
+	// Only officers who can be rostered on some day of the week take part
+	var officers []models.Officer
+	for _, officer := range all {
+		for d := 0; d < 7; d++ {
+			if officer.ActiveOn(weekStart.AddDate(0, 0, d)) {
+				officers = append(officers, officer)
+				break
+			}
+		}
+	}
+
+	byID := make(map[uint]models.Officer, len(officers))
+	for _, officer := range officers {
+		byID[officer.ID] = officer
+	}
+
+	templates := append([]models.ShiftTemplate(nil), rules.Templates...)
+	sort.SliceStable(templates, func(i, j int) bool { return templates[i].SortOrder < templates[j].SortOrder })
+
+	// Resolve templates to officers; each officer follows at most one template
+	skipped := understaffedTemplates(templates, officers)
+	claimed := make(map[uint]bool)
+	var pinned []pinnedOfficer
+	for _, t := range templates {
+		if skipped[templateSelector(t)] {
+			continue
+		}
+		for _, officer := range templateOfficers(t, officers) {
+			if claimed[officer.ID] {
+				continue
+			}
+			claimed[officer.ID] = true
+			pinned = append(pinned, pinnedOfficer{officer: officer, template: t})
+		}
+	}
+
+	definitions, err := loadShiftDefinitions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Remaining regular officers rotate with their team
+	teamOfficers := make(map[models.ShiftType][]models.Officer)
+	var restingOfficers []models.Officer
+	for _, officer := range officers {
+		if claimed[officer.ID] || officer.Role != models.RoleRegular {
+			continue
+		}
+		if assignments[officer.Team] == models.CycleRest {
+			restingOfficers = append(restingOfficers, officer)
+		} else {
+			shiftType := models.ShiftType(assignments[officer.Team])
+			teamOfficers[shiftType] = append(teamOfficers[shiftType], officer)
+		}
+	}
+
+	// Rostered days off continue from recent weeks so they even out across the team
+	history, err := loadOffDayHistory(db, weekStart.AddDate(0, 0, -7*offDayLookbackWeeks), weekStart)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves, err := loadApprovedLeaves(db, weekStart, weekStart.AddDate(0, 0, 6))
+	if err != nil {
+		return nil, err
+	}
+
+	holidays, err := loadHolidays(db, weekStart, weekStart.AddDate(0, 0, 6))
+	if err != nil {
+		return nil, err
+	}
+
+	var shifts []models.Shift
+
+	// Relief duties so far this week, so acting sergeants share them
+	reliefCount := make(map[uint]int)
+
+	for dayOffset := 0; dayOffset < 7; dayOffset++ {
+		currentDate := weekStart.AddDate(0, 0, dayOffset)
+		weekday := currentDate.Weekday()
+		dayStart := len(shifts)
+
+		// Female officers on duty per shift, towards the rule set's coverage minimum
+		femalesOnDuty := make(map[models.ShiftType]int)
+		availableFemale := func(officer models.Officer) bool {
+			return officer.Gender == models.GenderFemale && findLeave(leaves, officer.ID, currentDate) == nil
+		}
+
+		// Sergeants and acting sergeants on duty per shift, for shifts that need a supervisor
+		supervisorsOnDuty := make(map[models.ShiftType]int)
+		availableSupervisor := func(officer models.Officer) bool {
+			return officer.CanSupervise() && findLeave(leaves, officer.ID, currentDate) == nil
+		}
+
+		// Fixed templates: on their shift every day except their days off
+		for _, p := range pinned {
+			if !p.officer.ActiveOn(currentDate) {
+				continue
+			}
+			status := models.StatusOnDuty
+			if p.template.IsDayOff(weekday) {
+				status = models.StatusOffDuty
+			} else {
+				if availableFemale(p.officer) {
+					femalesOnDuty[p.template.ShiftType]++
+				}
+				if availableSupervisor(p.officer) {
+					supervisorsOnDuty[p.template.ShiftType]++
+				}
+			}
+			shifts = append(shifts, models.Shift{
+				OfficerID: p.officer.ID,
+				Date:      currentDate,
+				ShiftType: p.template.ShiftType,
+				Status:    status,
+			})
+		}
+
+		// Teams resting this week are off every day
+		restRows := make(map[uint]int)
+		for _, officer := range restingOfficers {
+			if !officer.ActiveOn(currentDate) {
+				continue
+			}
+			restRows[officer.ID] = len(shifts)
+			shifts = append(shifts, models.Shift{
+				OfficerID: officer.ID,
+				Date:      currentDate,
+				ShiftType: definitions[0].Code,
+				Status:    models.StatusOffDuty,
+			})
+		}
+
+		// Team shifts, reduced by the staffing rule for this weekday
+		for _, definition := range definitions {
+			shiftType := definition.Code
+			rule := staffingRuleFor(rules, weekday, holidays[currentDate.Format("2006-01-02")] != "", shiftType)
+
+			// Officers who cannot be rostered that day get no shift
+			var working []models.Officer
+			for _, officer := range teamOfficers[shiftType] {
+				if officer.ActiveOn(currentDate) {
+					working = append(working, officer)
+				}
+			}
+
+			// Female officers still needed for coverage are kept on duty
+			reserved := make(map[uint]bool)
+			need := minFemale(rules, weekday, shiftType) - femalesOnDuty[shiftType]
+			for _, officer := range working {
+				if len(reserved) >= need {
+					break
+				}
+				if availableFemale(officer) {
+					reserved[officer.ID] = true
+				}
+			}
+
+			// So is an acting sergeant when the shift has no other supervisor
+			if needsSupervisor(rules, weekday, shiftType) && supervisorsOnDuty[shiftType] == 0 {
+				var candidates []models.Officer
+				for _, officer := range working {
+					if availableSupervisor(officer) {
+						candidates = append(candidates, officer)
+					}
+				}
+				if relief := pickRelief(candidates, reliefCount); relief != nil {
+					reserved[relief.ID] = true
+				}
+			}
+
+			// Only MaxOnDuty officers work, reserved officers first. The rest are stood down by who is due a
+			// day off, starting with any already away on leave, so the same officers do not always work.
+			onDuty := make(map[uint]bool)
+			limit := len(working)
+			if rule != nil && rule.MaxOnDuty > 0 && rule.MaxOnDuty < limit {
+				limit = rule.MaxOnDuty
+			}
+			var away, others []models.Officer
+			for _, officer := range working {
+				switch {
+				case reserved[officer.ID]:
+					if len(onDuty) < limit {
+						onDuty[officer.ID] = true
+					}
+				case findLeave(leaves, officer.ID, currentDate) != nil:
+					away = append(away, officer)
+				default:
+					others = append(others, officer)
+				}
+			}
+			standDown := len(away) + len(others) - (limit - len(onDuty))
+			for _, officer := range away {
+				if standDown > 0 {
+					standDown--
+					continue
+				}
+				onDuty[officer.ID] = true
+			}
+			stoodDown := pickOffDuty(history, shiftType, others, standDown, currentDate)
+			for _, officer := range others {
+				if !stoodDown[officer.ID] {
+					onDuty[officer.ID] = true
+				}
+			}
+
+			// OffCount more are chosen by who is due a day off
+			var offToday map[uint]bool
+			if rule != nil && rule.OffCount > 0 {
+				// Officers on leave that day are already away and are not given a rostered day off
+				var available []models.Officer
+				for _, officer := range working {
+					if onDuty[officer.ID] && !reserved[officer.ID] && findLeave(leaves, officer.ID, currentDate) == nil {
+						available = append(available, officer)
+					}
+				}
+				offToday = pickOffDuty(history, shiftType, available, rule.OffCount, currentDate)
+			}
+
+			for _, officer := range working {
+				status := models.StatusOnDuty
+				if !onDuty[officer.ID] || offToday[officer.ID] {
+					status = models.StatusOffDuty
+					if findLeave(leaves, officer.ID, currentDate) == nil {
+						history.record(shiftType, officer.ID, currentDate)
+					}
+				} else {
+					if availableFemale(officer) {
+						femalesOnDuty[shiftType]++
+					}
+					if availableSupervisor(officer) {
+						supervisorsOnDuty[shiftType]++
+					}
+				}
+				shifts = append(shifts, models.Shift{
+					OfficerID: officer.ID,
+					Date:      currentDate,
+					ShiftType: shiftType,
+					Status:    status,
+				})
+			}
+		}
+
+		// Coverage the working teams cannot meet is drawn from female officers of resting teams
+		for _, definition := range definitions {
+			shiftType := definition.Code
+			for _, officer := range restingOfficers {
+				if femalesOnDuty[shiftType] >= minFemale(rules, weekday, shiftType) {
+					break
+				}
+				row, ok := restRows[officer.ID]
+				if !ok || shifts[row].Status != models.StatusOffDuty || !availableFemale(officer) {
+					continue
+				}
+				shifts[row].ShiftType = shiftType
+				shifts[row].Status = models.StatusOnDuty
+				femalesOnDuty[shiftType]++
+				if availableSupervisor(officer) {
+					supervisorsOnDuty[shiftType]++
+				}
+			}
+		}
+
+		// Shifts without a sergeant are supervised by a relief: an acting sergeant already on duty,
+		// or failing that one called in from a resting team
+		for _, definition := range definitions {
+			shiftType := definition.Code
+			if !needsSupervisor(rules, weekday, shiftType) {
+				continue
+			}
+
+			rows := make(map[uint]int)
+			var onShift, resting []models.Officer
+			hasSergeant := false
+			for i := dayStart; i < len(shifts); i++ {
+				officer := byID[shifts[i].OfficerID]
+				if !availableSupervisor(officer) {
+					continue
+				}
+				if shifts[i].Status == models.StatusOnDuty && shifts[i].ShiftType == shiftType {
+					if officer.Role == models.RoleSergeant {
+						hasSergeant = true
+					}
+					rows[officer.ID] = i
+					onShift = append(onShift, officer)
+				} else if row, ok := restRows[officer.ID]; ok && row == i && shifts[i].Status == models.StatusOffDuty {
+					rows[officer.ID] = i
+					resting = append(resting, officer)
+				}
+			}
+			if hasSergeant {
+				continue
+			}
+
+			relief := pickRelief(onShift, reliefCount)
+			if relief == nil {
+				relief = pickRelief(resting, reliefCount)
+			}
+			if relief == nil {
+				continue
+			}
+			row := rows[relief.ID]
+			shifts[row].ShiftType = shiftType
+			shifts[row].Status = models.StatusOnDuty
+			shifts[row].Relief = true
+			reliefCount[relief.ID]++
+		}
+	}
+
+	// Officers on approved leave are marked on_leave instead of their rostered status
+	for i := range shifts {
+		if findLeave(leaves, shifts[i].OfficerID, shifts[i].Date) != nil {
+			shifts[i].StatusBeforeLeave = shifts[i].Status
+			shifts[i].Status = models.StatusOnLeave
+		}
+	}
+
+	return shifts, nil
+}
+
+// needsSupervisor reports whether a shift must have a sergeant or acting sergeant on duty
+func needsSupervisor(rules models.RuleSet, weekday time.Weekday, shiftType models.ShiftType) bool {
+	rule := coverageRuleFor(rules, weekday, shiftType)
+	return rule != nil && rule.RequireSupervisor
+}
+
+// pickRelief chooses the acting sergeant with the fewest relief duties so far, then lowest ID.
+// Sergeants are never picked as relief.
+func pickRelief(officers []models.Officer, reliefCount map[uint]int) *models.Officer {
+	var best *models.Officer
+	for i := range officers {
+		officer := &officers[i]
+		if !officer.ActingSergeant || officer.Role == models.RoleSergeant {
+			continue
+		}
+		if best == nil || reliefCount[officer.ID] < reliefCount[best.ID] ||
+			(reliefCount[officer.ID] == reliefCount[best.ID] && officer.ID < best.ID) {
+			best = officer
+		}
+	}
+	return best
+}
+
+// templateOfficers returns the officers a template applies to, in ID order
+func templateOfficers(t models.ShiftTemplate, officers []models.Officer) []models.Officer {
+	if t.OfficerID != nil {
+		for _, officer := range officers {
+			if officer.ID == *t.OfficerID {
+				return []models.Officer{officer}
+			}
+		}
+		return nil
+	}
+
+	var matches []models.Officer
+	for _, officer := range officers {
+		if (t.Role == "" || officer.Role == t.Role) && (t.Gender == "" || officer.Gender == t.Gender) {
+			matches = append(matches, officer)
+		}
+	}
+
+	if t.Position != nil {
+		if *t.Position < 0 || *t.Position >= len(matches) {
+			return nil
+		}
+		return matches[*t.Position : *t.Position+1]
+	}
+	return matches
+}
+
+// templateSelector identifies the officers a positional template chooses among, e.g. "regular/female"
+func templateSelector(t models.ShiftTemplate) string {
+	if t.OfficerID != nil || t.Position == nil {
+		return ""
+	}
+	return string(t.Role) + "/" + string(t.Gender)
+}
+
+// understaffedTemplates finds the positional template sets with fewer matching officers than templates, such
+// as the two female officer templates with one female officer. When those officers all belong to a team the
+// set is skipped, so they rotate with their team rather than working the same shift every week.
+func understaffedTemplates(templates []models.ShiftTemplate, officers []models.Officer) map[string]bool {
+	sets := make(map[string]int)
+	for _, t := range templates {
+		if selector := templateSelector(t); selector != "" {
+			sets[selector]++
+		}
+	}
+
+	skipped := make(map[string]bool)
+	for _, t := range templates {
+		selector := templateSelector(t)
+		if selector == "" || skipped[selector] {
+			continue
+		}
+		all := t
+		all.Position = nil
+		matches := templateOfficers(all, officers)
+		if len(matches) == 0 || len(matches) >= sets[selector] {
+			continue
+		}
+		regulars := true
+		for _, officer := range matches {
+			regulars = regulars && officer.Role == models.RoleRegular
+		}
+		skipped[selector] = regulars
+	}
+	return skipped
+}
+
+// staffingRuleFor finds the staffing rule for a team shift on a weekday. On a public holiday a holiday
+// rule for the shift takes precedence over the weekday rule.
+func staffingRuleFor(rules models.RuleSet, weekday time.Weekday, holiday bool, shiftType models.ShiftType) *models.StaffingRule {
+	var weekdayRule *models.StaffingRule
+	for i := range rules.Staffing {
+		rule := &rules.Staffing[i]
+		if rule.ShiftType != shiftType {
+			continue
+		}
+		if rule.Holiday {
+			if holiday {
+				return rule
+			}
+		} else if rule.Weekday == int(weekday) && weekdayRule == nil {
+			weekdayRule = rule
+		}
+	}
+	return weekdayRule
+}
@@ -0,0 +1,538 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/unidoc/unioffice/document"
+	"gorm.io/gorm"
+)
+
+// rotaImportDays are the day columns of a rota grid, Sunday first as in the exports
+var rotaImportDays = []string{"SUNDAY", "MONDAY", "TUESDAY", "WEDNESDAY", "THURSDAY", "FRIDAY", "SATURDAY"}
+
+// rotaImportDateFormats are the date formats accepted under the day names of a rota grid header
+var rotaImportDateFormats = []string{"02/01/06", "2/1/06", "02/01/2006", "2/1/2006", "2006-01-02", "2 Jan 2006", "2 Jan 06"}
+
+// ImportRotaGrid godoc
+// @Summary Import a week's rota from a DOCX or XLSX grid
+// @Description Upload a rota laid out like the weekly DOCX/XLSX export: a SHIFT TYPE x SUNDAY..SATURDAY grid with
+// @Description a row per shift definition plus DAY-OFF and ON LEAVE rows. Names are matched to officers as printed
+// @Description (uppercase, without Officer/Sgt. prefixes). The week's rotation and shifts are created only if every
+// @Description cell is understood and no officer listed already has a shift that day; otherwise the problems are listed
+// @Description and nothing is saved. Leave rows create approved leave of the type noted after the name, e.g. (AL).
+// @Tags admin
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "DOCX or XLSX rota"
+// @Param sheet formData string false "XLSX sheet name or number (from 1), default the first"
+// @Param week_start formData string false "Week start date (Sunday, YYYY-MM-DD), default from the header dates"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/import-rota [post]
+func ImportRotaGrid(c *gin.Context) {
+	rows, err := readRotaGrid(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	headerIndex := -1
+	for i, row := range rows {
+		if isRotaHeader(row) {
+			headerIndex = i
+			break
+		}
+	}
+	if headerIndex < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No rota grid found (expected a header row with SUNDAY to SATURDAY)"})
+		return
+	}
+
+	var weekStart time.Time
+	if s := c.PostForm("week_start"); s != "" {
+		weekStart, err = time.Parse("2006-01-02", s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+			return
+		}
+	} else if weekStart, err = rotaHeaderWeekStart(rows[headerIndex]); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if weekStart.Weekday() != time.Sunday {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "week_start must be a Sunday"})
+		return
+	}
+
+	var existing models.WeekRotation
+	if database.DB.Where("week_start = ?", weekStart).First(&existing).Error == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rotation already exists for this week. Delete it first with DELETE /shifts/week if you want to re-import."})
+		return
+	}
+
+	definitions, err := loadShiftDefinitions(database.DB)
+	if err != nil || len(definitions) == 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shift definitions"})
+		return
+	}
+	var officers []models.Officer
+	if err := database.DB.Find(&officers).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load officers"})
+		return
+	}
+	byName := make(map[string][]models.Officer)
+	for _, officer := range officers {
+		key := rotaNameKey(officer.Name)
+		byName[key] = append(byName[key], officer)
+	}
+
+	weekEnd := weekStart.AddDate(0, 0, 6)
+	approvedLeaves, err := loadApprovedLeaves(database.DB, weekStart, weekEnd)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load leave"})
+		return
+	}
+
+	// Read the grid; off-duty and leave shifts get their shift type once the teams are known
+	var shifts []models.Shift
+	var leaveDays []rotaLeaveDay
+	var errors []string
+	teams := make(map[uint]int)
+	listed := make(map[string]bool)
+	for _, row := range rows[headerIndex+1:] {
+		label := rotaCellLines(csvColumn(row, 0))
+		if len(label) == 0 {
+			continue
+		}
+		status, shiftType, ok := rotaRowKind(label[0], definitions)
+		if !ok {
+			errors = append(errors, fmt.Sprintf("Unknown row: %s (use a shift definition label, DAY-OFF or ON LEAVE)", label[0]))
+			continue
+		}
+
+		for i := range rotaImportDays {
+			date := weekStart.AddDate(0, 0, i)
+			for _, line := range rotaCellLines(csvColumn(row, i+1)) {
+				name, relief, notes := rotaImportName(line)
+				where := fmt.Sprintf("%s %s", strings.ToUpper(date.Format("Mon 02/01")), label[0])
+
+				matches := byName[rotaNameKey(name)]
+				if len(matches) == 0 {
+					errors = append(errors, fmt.Sprintf("%s: Officer not found: %s", where, name))
+					continue
+				}
+				if len(matches) > 1 {
+					errors = append(errors, fmt.Sprintf("%s: %s matches %d officers", where, name, len(matches)))
+					continue
+				}
+				officer := matches[0]
+
+				key := officerDateKey(officer.ID, date)
+				if listed[key] {
+					errors = append(errors, fmt.Sprintf("%s: %s is listed more than once that day", where, name))
+					continue
+				}
+				listed[key] = true
+				teams[officer.ID] = officer.Team
+
+				// Leave rows are backed by approved leave of the type noted, e.g. (AL)
+				approved := findLeave(approvedLeaves, officer.ID, date)
+				if status == models.StatusOnLeave && approved == nil {
+					leaveType, ok := rotaLeaveType(notes)
+					if !ok {
+						errors = append(errors, fmt.Sprintf("%s: Unknown leave type for %s: (%s) (use AL, SL, TRG or LV)", where, name, strings.Join(notes, ") (")))
+						continue
+					}
+					leaveDays = append(leaveDays, rotaLeaveDay{officerID: officer.ID, date: date, leaveType: leaveType})
+				}
+				if status != models.StatusOnLeave && approved != nil {
+					errors = append(errors, fmt.Sprintf("%s: %s is on approved leave that day", where, name))
+					continue
+				}
+
+				shifts = append(shifts, models.Shift{
+					OfficerID: officer.ID,
+					Date:      date,
+					ShiftType: shiftType,
+					Status:    status,
+					Manual:    true,
+					Relief:    relief && status == models.StatusOnDuty,
+				})
+			}
+		}
+	}
+	if len(errors) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rota not imported", "errors": errors})
+		return
+	}
+	if len(shifts) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rota grid has no officers"})
+		return
+	}
+
+	// Officers have a single row per day, so shifts already saved for the week (e.g. from a shifts CSV) conflict
+	var existingShifts []models.Shift
+	if err := database.DB.Preload("Officer", withFormerOfficers).
+		Where("date >= ? AND date <= ?", weekStart, weekEnd).
+		Order("date ASC, officer_id ASC").
+		Find(&existingShifts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load existing shifts"})
+		return
+	}
+	var conflicts []string
+	for _, shift := range existingShifts {
+		if listed[officerDateKey(shift.OfficerID, shift.Date)] {
+			conflicts = append(conflicts, fmt.Sprintf("%s already has a %s shift on %s (shift %d)",
+				shift.Officer.Name, shift.ShiftType, shift.Date.Format("2006-01-02"), shift.ID))
+		}
+	}
+	if len(conflicts) > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Rota not imported: the week already has shifts for these officers", "errors": conflicts})
+		return
+	}
+
+	// The team on each shift is the one most of its officers belong to
+	shiftTeams := make(map[string]int, len(definitions))
+	teamShift := make(map[int]models.ShiftType)
+	for _, definition := range definitions {
+		team := rotaShiftTeam(shifts, teams, definition.Code)
+		shiftTeams[string(definition.Code)] = team
+		if _, ok := teamShift[team]; !ok && team != 0 {
+			teamShift[team] = definition.Code
+		}
+	}
+	rotation := models.WeekRotation{
+		WeekStart:      weekStart,
+		DayShiftTeam:   shiftTeams[string(models.ShiftDay)],
+		NightShiftTeam: shiftTeams[string(models.ShiftNight)],
+	}
+	for i := range shifts {
+		if shifts[i].ShiftType != "" {
+			continue
+		}
+		shifts[i].ShiftType = definitions[0].Code
+		if shiftType, ok := teamShift[teams[shifts[i].OfficerID]]; ok {
+			shifts[i].ShiftType = shiftType
+		}
+	}
+
+	// Place the week in the active cycle so that generation carries on from it
+	cycle, err := loadActiveCycle(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load rotation cycle"})
+		return
+	}
+	for w := 0; w < cycle.Length(); w++ {
+		matches := true
+		for code, team := range shiftTeams {
+			if cycle.TeamOn(w, code) != team {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			rotation.CycleWeek = w
+			if cycle.ID > 0 {
+				rotation.CycleID = &cycle.ID
+			}
+			break
+		}
+	}
+
+	leaves := rotaImportLeaves(leaveDays)
+	now := time.Now()
+	for i := range leaves {
+		leaves[i].ReviewedAt = &now
+		if userID, exists := c.Get("userID"); exists {
+			id := userID.(uint)
+			leaves[i].ReviewedBy = &id
+		}
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rotation).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&shifts).Error; err != nil {
+			return err
+		}
+		if len(leaves) > 0 {
+			return tx.Create(&leaves).Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save rota"})
+		return
+	}
+
+	response := gin.H{
+		"message":          "Rota imported successfully",
+		"week_start":       weekStart.Format("2006-01-02"),
+		"day_shift_team":   rotation.DayShiftTeam,
+		"night_shift_team": rotation.NightShiftTeam,
+		"shift_teams":      shiftTeams,
+		"shifts_created":   len(shifts),
+		"leave_created":    len(leaves),
+	}
+	if rotation.CycleID != nil {
+		response["cycle_week"] = rotation.CycleWeek
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// readRotaGrid reads the rows of the uploaded "file" form field, a DOCX (its rota table) or XLSX (the sheet named
+// or numbered by the "sheet" form field). A cell's lines are joined with newlines.
+func readRotaGrid(c *gin.Context) ([][]string, error) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		return nil, errors.New("No file uploaded")
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return nil, errors.New("Cannot open file")
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".docx":
+		return readDOCXGrid(f, file.Size)
+	case ".xlsx":
+		table, err := readXLSXTable(f, file.Size, c.PostForm("sheet"))
+		if err != nil {
+			return nil, err
+		}
+		return append([][]string{table.header}, table.rows...), nil
+	default:
+		return nil, errors.New("Upload the rota as a .docx or .xlsx file")
+	}
+}
+
+// readDOCXGrid returns the rows of the first table in the document with a rota header row
+func readDOCXGrid(f multipart.File, size int64) ([][]string, error) {
+	doc, err := document.Read(f, size)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read DOCX file: %v", err)
+	}
+	defer doc.Close()
+
+	for _, table := range doc.Tables() {
+		var rows [][]string
+		found := false
+		for _, row := range table.Rows() {
+			var cells []string
+			for _, cell := range row.Cells() {
+				var lines []string
+				for _, para := range cell.Paragraphs() {
+					var text strings.Builder
+					for _, run := range para.Runs() {
+						for _, content := range run.X().EG_RunInnerContent {
+							switch {
+							case content.T != nil:
+								text.WriteString(content.T.Content)
+							case content.Br != nil:
+								text.WriteString("\n")
+							case content.Tab != nil:
+								text.WriteString(" ")
+							}
+						}
+					}
+					lines = append(lines, text.String())
+				}
+				cells = append(cells, strings.Join(lines, "\n"))
+			}
+			rows = append(rows, cells)
+			found = found || isRotaHeader(cells)
+		}
+		if found {
+			return rows, nil
+		}
+	}
+	return nil, errors.New("No rota table found in the document")
+}
+
+// isRotaHeader reports whether a row is the grid header: a label column, then SUNDAY to SATURDAY
+// (or their first three letters)
+func isRotaHeader(row []string) bool {
+	if len(row) < len(rotaImportDays)+1 {
+		return false
+	}
+	for i, day := range rotaImportDays {
+		lines := rotaCellLines(row[i+1])
+		if len(lines) == 0 || !strings.HasPrefix(strings.ToUpper(lines[0]), day[:3]) {
+			return false
+		}
+	}
+	return true
+}
+
+// rotaHeaderWeekStart finds the week's Sunday from the first date under a day name in the header
+func rotaHeaderWeekStart(header []string) (time.Time, error) {
+	for i := range rotaImportDays {
+		for _, line := range rotaCellLines(header[i+1]) {
+			for _, format := range rotaImportDateFormats {
+				if date, err := time.Parse(format, line); err == nil {
+					return date.AddDate(0, 0, -i), nil
+				}
+			}
+			if date, err := parseImportDate(line); err == nil {
+				return date.AddDate(0, 0, -i), nil
+			}
+		}
+	}
+	return time.Time{}, errors.New("week_start is required when the header has no dates")
+}
+
+// rotaRowKind maps a row label to the status and shift type of its officers. Day-off and leave rows have no
+// shift type until the teams are known.
+func rotaRowKind(label string, definitions []models.ShiftDefinition) (models.DutyStatus, models.ShiftType, bool) {
+	key := normalizeRotaLabel(label)
+	switch key {
+	case "DAYOFF", "DAYSOFF", "OFF", "OFFDUTY", "REST":
+		return models.StatusOffDuty, "", true
+	case "ONLEAVE", "LEAVE":
+		return models.StatusOnLeave, "", true
+	}
+	for _, d := range definitions {
+		code := normalizeRotaLabel(string(d.Code))
+		if key == normalizeRotaLabel(d.Label) || key == code || key == code+"SHIFT" {
+			return models.StatusOnDuty, d.Code, true
+		}
+	}
+	return "", "", false
+}
+
+func normalizeRotaLabel(s string) string {
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToUpper(s))
+}
+
+// rotaCellLines splits a grid cell into its non-blank lines
+func rotaCellLines(cell string) []string {
+	var lines []string
+	for _, line := range strings.Split(cell, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// rotaImportName strips the notes the export adds after a name, e.g. "MOSES (A/SGT)" or "FAIDES (AL)". It
+// reports whether the officer was marked as an acting sergeant and returns the other notes.
+func rotaImportName(line string) (string, bool, []string) {
+	relief := false
+	var notes []string
+	for strings.HasSuffix(line, ")") {
+		i := strings.LastIndex(line, "(")
+		if i <= 0 {
+			break
+		}
+		note := strings.TrimSpace(line[i+1 : len(line)-1])
+		if strings.EqualFold(note, "A/SGT") {
+			relief = true
+		} else {
+			notes = append(notes, note)
+		}
+		line = strings.TrimSpace(line[:i])
+	}
+	return line, relief, notes
+}
+
+// rotaLeaveDay is a day an imported rota shows an officer on leave that no approved leave covers yet
+type rotaLeaveDay struct {
+	officerID uint
+	date      time.Time
+	leaveType models.LeaveType
+}
+
+// rotaLeaveType reads the leave code noted after a name on a leave row; no code is other leave
+func rotaLeaveType(notes []string) (models.LeaveType, bool) {
+	if len(notes) == 0 {
+		return models.LeaveOther, true
+	}
+	for _, note := range notes {
+		for _, leaveType := range []models.LeaveType{models.LeaveAnnual, models.LeaveSick, models.LeaveTraining, models.LeaveOther} {
+			if strings.EqualFold(note, leaveCode(leaveType)) {
+				return leaveType, true
+			}
+		}
+	}
+	return "", false
+}
+
+// rotaImportLeaves turns leave days into approved leave records, one per run of consecutive days of the
+// same type for an officer
+func rotaImportLeaves(days []rotaLeaveDay) []models.Leave {
+	sort.Slice(days, func(i, j int) bool {
+		if days[i].officerID != days[j].officerID {
+			return days[i].officerID < days[j].officerID
+		}
+		return days[i].date.Before(days[j].date)
+	})
+
+	var leaves []models.Leave
+	for _, day := range days {
+		if n := len(leaves); n > 0 {
+			last := &leaves[n-1]
+			if last.OfficerID == day.officerID && last.Type == day.leaveType && last.EndDate.AddDate(0, 0, 1).Equal(day.date) {
+				last.EndDate = day.date
+				continue
+			}
+		}
+		leaves = append(leaves, models.Leave{
+			OfficerID: day.officerID,
+			StartDate: day.date,
+			EndDate:   day.date,
+			Type:      day.leaveType,
+			Status:    models.LeaveApproved,
+			Reason:    "Imported from rota",
+		})
+	}
+	return leaves
+}
+
+// rotaNameKey reverses rotaDisplayName enough to compare a printed name with an officer's name
+func rotaNameKey(name string) string {
+	name = strings.Join(strings.Fields(strings.ToUpper(name)), " ")
+	for _, prefix := range []string{"OFFICER ", "SGT. ", "SGT "} {
+		name = strings.TrimPrefix(name, prefix)
+	}
+	return name
+}
+
+// rotaShiftTeam returns the team most officers on duty on a shift belong to, the lowest on a tie, or 0 if none
+func rotaShiftTeam(shifts []models.Shift, teams map[uint]int, shiftType models.ShiftType) int {
+	counts := make(map[int]int)
+	for _, shift := range shifts {
+		if shift.Status == models.StatusOnDuty && shift.ShiftType == shiftType {
+			counts[teams[shift.OfficerID]]++
+		}
+	}
+
+	var ordered []int
+	for team := range counts {
+		ordered = append(ordered, team)
+	}
+	sort.Ints(ordered)
+
+	best := 0
+	for _, team := range ordered {
+		if best == 0 || counts[team] > counts[best] {
+			best = team
+		}
+	}
+	return best
+}
@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Fill colours of the month grid's off-duty and leave cells
+const (
+	monthOffColour   = "#E7E6E6"
+	monthLeaveColour = "#E2EFDA"
+)
+
+// officerGrid holds the officer x day grid used by the monthly PDF and DOCX exports and the XLSX shift export
+type officerGrid struct {
+	days          []time.Time
+	holidays      map[string]string // holiday name by YYYY-MM-DD
+	definitions   []models.ShiftDefinition
+	abbreviations map[models.ShiftType]string
+	officers      []officerGridRow
+}
+
+// officerGridRow is one officer's row of the grid with their totals
+type officerGridRow struct {
+	name   string
+	team   int
+	cells  []officerGridCell // one per day of the grid
+	shifts map[models.ShiftType]int
+	off    int
+	leave  int
+	hours  float64
+}
+
+// officerGridCell is the code shown for an officer on a day, e.g. D, N, OFF or AL
+type officerGridCell struct {
+	code   string
+	colour string // #RRGGBB fill, empty for none
+}
+
+// monthQuery parses the month query parameter, responding with an error if it is missing or invalid
+func monthQuery(c *gin.Context) (time.Time, bool) {
+	monthStr := c.Query("month")
+	if monthStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "month is required"})
+		return time.Time{}, false
+	}
+	month, err := time.Parse("2006-01", monthStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid month format, use YYYY-MM"})
+		return time.Time{}, false
+	}
+	return month, true
+}
+
+// loadMonthGrid organizes a calendar month's shifts into one row per rostered officer. It returns false when
+// the month has no shifts.
+func loadMonthGrid(month time.Time) (officerGrid, bool) {
+	from := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, -1)
+
+	var shifts []models.Shift
+	database.DB.Preload("Officer", withFormerOfficers).
+		Where("date >= ? AND date <= ?", from, to).
+		Find(&shifts)
+	if len(shifts) == 0 {
+		return officerGrid{}, false
+	}
+	return loadOfficerGrid(from, to, shifts), true
+}
+
+// loadOfficerGrid organizes shifts from from to to (inclusive) into one row per officer. Shifts must have
+// their Officer loaded.
+func loadOfficerGrid(from, to time.Time, shifts []models.Shift) officerGrid {
+	var grid officerGrid
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		grid.days = append(grid.days, d)
+	}
+
+	leaves, _ := loadApprovedLeaves(database.DB, from, to)
+	grid.holidays, _ = loadHolidays(database.DB, from, to)
+	grid.definitions, _ = loadShiftDefinitions(database.DB)
+	grid.abbreviations = shiftAbbreviations(grid.definitions)
+
+	rows := make(map[uint]*officerGridRow)
+	for _, shift := range shifts {
+		dayIndex := int(shift.Date.Sub(from).Hours() / 24)
+		if dayIndex < 0 || dayIndex >= len(grid.days) {
+			continue
+		}
+
+		row, ok := rows[shift.OfficerID]
+		if !ok {
+			row = &officerGridRow{
+				name:   rotaDisplayName(shift.Officer.Name),
+				team:   shift.Officer.Team,
+				cells:  make([]officerGridCell, len(grid.days)),
+				shifts: make(map[models.ShiftType]int),
+			}
+			rows[shift.OfficerID] = row
+		}
+
+		cell := &row.cells[dayIndex]
+		switch shift.Status {
+		case models.StatusOnLeave:
+			cell.code = "LV"
+			if leave := findLeave(leaves, shift.OfficerID, shift.Date); leave != nil {
+				cell.code = leaveCode(leave.Type)
+			}
+			cell.colour = monthLeaveColour
+			row.leave++
+		case models.StatusOffDuty:
+			cell.code = "OFF"
+			cell.colour = monthOffColour
+			row.off++
+		default:
+			cell.code = grid.abbreviations[shift.ShiftType]
+			if cell.code == "" {
+				cell.code = strings.ToUpper(string(shift.ShiftType))
+			}
+			if shift.Relief {
+				cell.code += "*"
+			}
+			if definition := findShiftDefinition(grid.definitions, shift.ShiftType); definition != nil {
+				cell.colour = definition.Colour
+				row.hours += definition.Hours()
+			}
+			row.shifts[shift.ShiftType]++
+		}
+	}
+
+	for _, row := range rows {
+		grid.officers = append(grid.officers, *row)
+	}
+	sort.Slice(grid.officers, func(i, j int) bool {
+		if grid.officers[i].team != grid.officers[j].team {
+			return grid.officers[i].team < grid.officers[j].team
+		}
+		return grid.officers[i].name < grid.officers[j].name
+	})
+	return grid
+}
+
+// legend explains the codes used in the grid
+func (g officerGrid) legend() string {
+	var parts []string
+	for _, d := range g.definitions {
+		parts = append(parts, fmt.Sprintf("%s = %s %s-%s", g.abbreviations[d.Code], d.Label, d.StartTime, d.EndTime))
+	}
+	parts = append(parts, "OFF = rostered off", "AL/SL/TRG/LV = annual, sick, training, other leave", "* = relief supervisor")
+	return strings.Join(parts, "   ")
+}
+
+// holidayList names the grid's public holidays, e.g. "25 Dec Christmas Day"
+func (g officerGrid) holidayList() string {
+	var parts []string
+	for _, d := range g.days {
+		if name := g.holidays[d.Format("2006-01-02")]; name != "" {
+			parts = append(parts, fmt.Sprintf("%s %s", d.Format("2 Jan"), name))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// shiftAbbreviations gives each shift definition a short code for the month grid: the first letter of its code,
+// or as many letters as it takes to tell it apart from the shifts before it
+func shiftAbbreviations(definitions []models.ShiftDefinition) map[models.ShiftType]string {
+	abbreviations := make(map[models.ShiftType]string, len(definitions))
+	used := make(map[string]bool)
+	for _, d := range definitions {
+		code := strings.ToUpper(string(d.Code))
+		abbreviation := code
+		for n := 1; n <= len(code); n++ {
+			if !used[code[:n]] {
+				abbreviation = code[:n]
+				break
+			}
+		}
+		used[abbreviation] = true
+		abbreviations[d.Code] = abbreviation
+	}
+	return abbreviations
+}
@@ -49,46 +49,7 @@ func GetWeekRotaPDF(c *gin.Context) {
 	}
 
 	weekEnd := weekStart.AddDate(0, 0, 6)
-
-	// Get all shifts for the week
-	var shifts []models.Shift
-	database.DB.Preload("Officer").
-		Where("date >= ? AND date <= ?", weekStart, weekEnd).
-		Order("date ASC, shift_type ASC, officer_id ASC").
-		Find(&shifts)
-
-	// Organize shifts by day
-	type dayData struct {
-		date       time.Time
-		dayShift   []string // on duty
-		nightShift []string // on duty
-		leave      []string // off duty
-	}
-	days := make([]dayData, 7)
-	for i := 0; i < 7; i++ {
-		days[i] = dayData{date: weekStart.AddDate(0, 0, i)}
-	}
-
-	for _, shift := range shifts {
-		dayIndex := int(shift.Date.Sub(weekStart).Hours() / 24)
-		if dayIndex < 0 || dayIndex > 6 {
-			continue
-		}
-
-		// Get officer name (remove prefixes, uppercase)
-		name := shift.Officer.Name
-		name = strings.TrimPrefix(name, "Officer ")
-		name = strings.TrimPrefix(name, "Sgt. ")
-		name = strings.ToUpper(name)
-
-		if shift.Status == models.StatusOffDuty {
-			days[dayIndex].leave = append(days[dayIndex].leave, name)
-		} else if shift.ShiftType == models.ShiftDay {
-			days[dayIndex].dayShift = append(days[dayIndex].dayShift, name)
-		} else {
-			days[dayIndex].nightShift = append(days[dayIndex].nightShift, name)
-		}
-	}
+	days, rows := loadRotaGrid(weekStart)
 
 	// Create PDF - Landscape A4
 	pdf := gofpdf.New("L", "mm", "A4", "")
@@ -110,7 +71,14 @@ func GetWeekRotaPDF(c *gin.Context) {
 	pdf.SetFont("Arial", "B", 9)
 	pdf.SetFillColor(192, 192, 192)
 
+	// Public holidays get a third header line with their name
 	headerHeight := 12.0
+	for _, d := range days {
+		if d.holiday != "" {
+			headerHeight = 17.0
+			break
+		}
+	}
 	pdf.CellFormat(shiftTypeWidth, headerHeight, "SHIFT TYPE", "1", 0, "C", true, 0, "")
 	dayNames := []string{"SUNDAY", "MONDAY", "TUESDAY", "WEDNESDAY", "THURSDAY", "FRIDAY", "SATURDAY"}
 	startY := pdf.GetY()
@@ -118,100 +86,228 @@ func GetWeekRotaPDF(c *gin.Context) {
 		x := 10 + shiftTypeWidth + float64(i)*dayWidth
 		pdf.SetXY(x, startY)
 		dateStr := days[i].date.Format("02/01/06")
+		if days[i].holiday != "" {
+			pdf.SetFillColor(255, 204, 204)
+		}
 		pdf.CellFormat(dayWidth, headerHeight, "", "1", 0, "C", true, 0, "")
+		pdf.SetFillColor(192, 192, 192)
 		pdf.SetXY(x, startY+1)
 		pdf.CellFormat(dayWidth, 5, day, "", 0, "C", false, 0, "")
 		pdf.SetXY(x, startY+6)
 		pdf.CellFormat(dayWidth, 5, dateStr, "", 0, "C", false, 0, "")
+		if days[i].holiday != "" {
+			pdf.SetFont("Arial", "I", 7)
+			pdf.SetXY(x, startY+11)
+			pdf.CellFormat(dayWidth, 5, fitText(pdf, strings.ToUpper(days[i].holiday), dayWidth-2), "", 0, "C", false, 0, "")
+			pdf.SetFont("Arial", "B", 9)
+		}
 	}
 	pdf.SetY(startY + headerHeight)
 
-	// Calculate row heights based on content
-	maxDayShift := 0
-	maxNightShift := 0
-	maxLeave := 0
-	for _, d := range days {
-		if len(d.dayShift) > maxDayShift {
-			maxDayShift = len(d.dayShift)
+	lineHeight := 4.5
+	pdf.SetFillColor(255, 255, 255)
+
+	for _, r := range rows {
+		// Row height fits the busiest day
+		maxNames := 0
+		for _, d := range days {
+			if len(r.names(d)) > maxNames {
+				maxNames = len(r.names(d))
+			}
 		}
-		if len(d.nightShift) > maxNightShift {
-			maxNightShift = len(d.nightShift)
+		rowHeight := float64(maxNames) * lineHeight
+		if rowHeight < 20 {
+			rowHeight = 20
 		}
-		if len(d.leave) > maxLeave {
-			maxLeave = len(d.leave)
+
+		// Shift rows show their times under the label, filled in the shift's colour
+		pdf.SetFont("Arial", "B", 9)
+		startY = pdf.GetY()
+		if r.colour != "" {
+			red, green, blue := hexRGB(r.colour)
+			pdf.SetFillColor(red, green, blue)
+		}
+		pdf.CellFormat(shiftTypeWidth, rowHeight, "", "1", 0, "C", r.colour != "", 0, "")
+		pdf.SetFillColor(255, 255, 255)
+		if r.times == "" {
+			pdf.SetXY(10, startY)
+			pdf.CellFormat(shiftTypeWidth, rowHeight, fitText(pdf, r.label, shiftTypeWidth-2), "", 0, "C", false, 0, "")
+		} else {
+			pdf.SetXY(10, startY+rowHeight/2-5)
+			pdf.CellFormat(shiftTypeWidth, 5, fitText(pdf, r.label, shiftTypeWidth-2), "", 0, "C", false, 0, "")
+			pdf.SetFont("Arial", "", 8)
+			pdf.SetXY(10, startY+rowHeight/2)
+			pdf.CellFormat(shiftTypeWidth, 5, r.times, "", 0, "C", false, 0, "")
 		}
-	}
 
-	lineHeight := 4.5
-	dayShiftHeight := float64(maxDayShift) * lineHeight
-	if dayShiftHeight < 20 {
-		dayShiftHeight = 20
+		pdf.SetFont("Arial", "", 8)
+		for i := 0; i < 7; i++ {
+			x := 10 + shiftTypeWidth + float64(i)*dayWidth
+			pdf.SetXY(x, startY)
+			content := strings.Join(r.names(days[i]), "\n")
+			// Draw cell border first, then add text without border
+			pdf.CellFormat(dayWidth, rowHeight, "", "1", 0, "", false, 0, "")
+			pdf.SetXY(x+1, startY+1)
+			pdf.MultiCell(dayWidth-2, lineHeight, content, "", "L", false)
+		}
+		pdf.SetY(startY + rowHeight)
 	}
-	nightShiftHeight := float64(maxNightShift) * lineHeight
-	if nightShiftHeight < 20 {
-		nightShiftHeight = 20
+
+	// Output
+	c.Header("Content-Type", "application/pdf")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=rota_%s.pdf", weekStartStr))
+
+	err = pdf.Output(c.Writer)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
 	}
-	leaveHeight := float64(maxLeave) * lineHeight
-	if leaveHeight < 20 {
-		leaveHeight = 20
+}
+
+// GetMonthRotaPDF godoc
+// @Summary Download monthly rota as PDF
+// @Description Generate and download a PDF of a calendar month's rota: one row per officer with a shift code
+// @Description for each day and their shift, day-off, leave and hour totals
+// @Tags rota
+// @Produce application/pdf
+// @Param month query string true "Month (YYYY-MM)"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /rota/month/pdf [get]
+func GetMonthRotaPDF(c *gin.Context) {
+	month, ok := monthQuery(c)
+	if !ok {
+		return
 	}
 
-	// DAY SHIFT row
-	pdf.SetFont("Arial", "B", 9)
-	pdf.SetFillColor(255, 255, 255)
+	grid, found := loadMonthGrid(month)
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No rota found for this month"})
+		return
+	}
 
-	startY = pdf.GetY()
-	pdf.CellFormat(shiftTypeWidth, dayShiftHeight, "DAY SHIFT", "1", 0, "C", false, 0, "")
+	// Create PDF - Landscape A4; pages are broken by hand so the header repeats
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(10, 10, 10)
+	pdf.SetAutoPageBreak(false, 10)
+	_, pageHeight := pdf.GetPageSize()
+	bottom := pageHeight - 10
 
-	pdf.SetFont("Arial", "", 8)
-	for i := 0; i < 7; i++ {
-		x := 10 + shiftTypeWidth + float64(i)*dayWidth
-		pdf.SetXY(x, startY)
-		content := strings.Join(days[i].dayShift, "\n")
-		// Draw cell border first, then add text without border
-		pdf.CellFormat(dayWidth, dayShiftHeight, "", "1", 0, "", false, 0, "")
-		pdf.SetXY(x+1, startY+1)
-		pdf.MultiCell(dayWidth-2, lineHeight, content, "", "L", false)
+	// Table dimensions: the days share what the name and total columns leave
+	nameWidth := 38.0
+	totalWidth := 8.0
+	totalLabels := []string{}
+	for _, d := range grid.definitions {
+		totalLabels = append(totalLabels, grid.abbreviations[d.Code])
 	}
-	pdf.SetY(startY + dayShiftHeight)
+	totalLabels = append(totalLabels, "OFF", "LV", "HRS")
+	dayWidth := (277 - nameWidth - totalWidth*float64(len(totalLabels))) / float64(len(grid.days))
+	rowHeight := 5.0
 
-	// NIGHT SHIFT row
-	pdf.SetFont("Arial", "B", 9)
-	startY = pdf.GetY()
-	pdf.CellFormat(shiftTypeWidth, nightShiftHeight, "NIGHT SHIFT", "1", 0, "C", false, 0, "")
+	header := func() {
+		pdf.AddPage()
+		pdf.SetFont("Arial", "B", 14)
+		pdf.CellFormat(0, 10, "Security Officer Duty Rota", "", 1, "C", false, 0, "")
+		pdf.SetFont("Arial", "", 12)
+		pdf.CellFormat(0, 8, month.Format("January 2006"), "", 1, "C", false, 0, "")
+		pdf.Ln(2)
 
-	pdf.SetFont("Arial", "", 8)
-	for i := 0; i < 7; i++ {
-		x := 10 + shiftTypeWidth + float64(i)*dayWidth
-		pdf.SetXY(x, startY)
-		content := strings.Join(days[i].nightShift, "\n")
-		pdf.CellFormat(dayWidth, nightShiftHeight, "", "1", 0, "", false, 0, "")
-		pdf.SetXY(x+1, startY+1)
-		pdf.MultiCell(dayWidth-2, lineHeight, content, "", "L", false)
+		// Day number over weekday initial; weekends shaded, public holidays pink
+		pdf.SetFont("Arial", "B", 7)
+		pdf.SetFillColor(192, 192, 192)
+		startY := pdf.GetY()
+		pdf.CellFormat(nameWidth, 8, "OFFICER", "1", 0, "C", true, 0, "")
+		for i, d := range grid.days {
+			x := 10 + nameWidth + float64(i)*dayWidth
+			switch {
+			case grid.holidays[d.Format("2006-01-02")] != "":
+				pdf.SetFillColor(255, 204, 204)
+			case d.Weekday() == time.Saturday || d.Weekday() == time.Sunday:
+				pdf.SetFillColor(217, 217, 217)
+			default:
+				pdf.SetFillColor(242, 242, 242)
+			}
+			pdf.SetXY(x, startY)
+			pdf.CellFormat(dayWidth, 8, "", "1", 0, "C", true, 0, "")
+			pdf.SetXY(x, startY)
+			pdf.CellFormat(dayWidth, 4, fmt.Sprint(d.Day()), "", 0, "C", false, 0, "")
+			pdf.SetXY(x, startY+4)
+			pdf.CellFormat(dayWidth, 4, d.Weekday().String()[:1], "", 0, "C", false, 0, "")
+		}
+		pdf.SetFillColor(192, 192, 192)
+		pdf.SetXY(10+nameWidth+float64(len(grid.days))*dayWidth, startY)
+		for _, label := range totalLabels {
+			pdf.CellFormat(totalWidth, 8, label, "1", 0, "C", true, 0, "")
+		}
+		pdf.SetY(startY + 8)
 	}
-	pdf.SetY(startY + nightShiftHeight)
 
-	// DAY-OFF row
-	pdf.SetFont("Arial", "B", 9)
-	startY = pdf.GetY()
-	pdf.CellFormat(shiftTypeWidth, leaveHeight, "DAY-OFF", "1", 0, "C", false, 0, "")
+	header()
+	for _, officer := range grid.officers {
+		if pdf.GetY()+rowHeight > bottom {
+			header()
+		}
 
-	pdf.SetFont("Arial", "", 8)
-	for i := 0; i < 7; i++ {
-		x := 10 + shiftTypeWidth + float64(i)*dayWidth
-		pdf.SetXY(x, startY)
-		content := strings.Join(days[i].leave, "\n")
-		pdf.CellFormat(dayWidth, leaveHeight, "", "1", 0, "", false, 0, "")
-		pdf.SetXY(x+1, startY+1)
-		pdf.MultiCell(dayWidth-2, lineHeight, content, "", "L", false)
+		pdf.SetFont("Arial", "", 7)
+		pdf.CellFormat(nameWidth, rowHeight, fitText(pdf, officer.name, nameWidth-2), "1", 0, "L", false, 0, "")
+
+		pdf.SetFont("Arial", "", 6)
+		for _, cell := range officer.cells {
+			if cell.colour != "" {
+				red, green, blue := hexRGB(cell.colour)
+				pdf.SetFillColor(red, green, blue)
+			}
+			pdf.CellFormat(dayWidth, rowHeight, cell.code, "1", 0, "C", cell.colour != "", 0, "")
+		}
+
+		pdf.SetFont("Arial", "B", 6)
+		for _, d := range grid.definitions {
+			pdf.CellFormat(totalWidth, rowHeight, fmt.Sprint(officer.shifts[d.Code]), "1", 0, "C", false, 0, "")
+		}
+		pdf.CellFormat(totalWidth, rowHeight, fmt.Sprint(officer.off), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(totalWidth, rowHeight, fmt.Sprint(officer.leave), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(totalWidth, rowHeight, fmt.Sprintf("%g", officer.hours), "1", 1, "C", false, 0, "")
 	}
 
+	// Legend, on a new page if the last one is full
+	pdf.SetFont("Arial", "", 7)
+	legend := grid.legend()
+	if holidays := grid.holidayList(); holidays != "" {
+		legend += "\nPublic holidays: " + holidays
+	}
+	lines := pdf.SplitLines([]byte(legend), 277)
+	if pdf.GetY()+3+float64(len(lines)+1)*4 > bottom {
+		pdf.AddPage()
+	}
+	pdf.Ln(3)
+	pdf.MultiCell(0, 4, legend, "", "L", false)
+
 	// Output
 	c.Header("Content-Type", "application/pdf")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=rota_%s.pdf", weekStartStr))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=rota_%s.pdf", month.Format("2006-01")))
 
-	err = pdf.Output(c.Writer)
-	if err != nil {
+	if err := pdf.Output(c.Writer); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate PDF"})
 	}
 }
+
+// hexRGB converts a #RRGGBB colour to its red, green and blue parts; invalid colours are white
+func hexRGB(hex string) (int, int, int) {
+	var red, green, blue int
+	if _, err := fmt.Sscanf(hex, "#%02x%02x%02x", &red, &green, &blue); err != nil {
+		return 255, 255, 255
+	}
+	return red, green, blue
+}
+
+// fitText shortens text with an ellipsis until it fits the width in the current font
+func fitText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
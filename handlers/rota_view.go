@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"securityrota-api/database"
@@ -14,15 +16,30 @@ import (
 type DayRota struct {
 	Date       string        `json:"date"`
 	DayOfWeek  string        `json:"day_of_week"`
-	DayShift   []OfficerDuty `json:"day_shift"`
-	NightShift []OfficerDuty `json:"night_shift"`
+	Holiday    string        `json:"holiday,omitempty"` // Public holiday name
+	Shifts     []ShiftDuty   `json:"shifts"`            // Every defined shift, in display order
+	DayShift   []OfficerDuty `json:"day_shift"`         // Same as the "day" entry of Shifts
+	NightShift []OfficerDuty `json:"night_shift"`       // Same as the "night" entry of Shifts
+	OnLeave    []OfficerDuty `json:"on_leave"`
+}
+
+// ShiftDuty represents the officers rostered on one shift of a day
+type ShiftDuty struct {
+	Code      models.ShiftType `json:"code"`
+	Label     string           `json:"label"`
+	StartTime string           `json:"start_time"`
+	EndTime   string           `json:"end_time"`
+	Colour    string           `json:"colour"`
+	Officers  []OfficerDuty    `json:"officers"`
 }
 
 // OfficerDuty represents an officer's duty status
 type OfficerDuty struct {
-	Name   string `json:"name"`
-	Role   string `json:"role"`
-	Status string `json:"status"` // on_duty or off_duty
+	Name      string `json:"name"`
+	Role      string `json:"role"`
+	Status    string `json:"status"`               // on_duty, off_duty or on_leave
+	Relief    bool   `json:"relief,omitempty"`     // acting sergeant supervising the shift
+	LeaveType string `json:"leave_type,omitempty"` // set when on_leave
 }
 
 // WeekRotaResponse represents the complete weekly rota
@@ -69,20 +86,23 @@ func GetWeekRota(c *gin.Context) {
 		return
 	}
 
-	nightShiftTeam := 1
-	if rotation.DayShiftTeam == 1 {
-		nightShiftTeam = 2
-	}
-
 	weekEnd := weekStart.AddDate(0, 0, 6)
 
 	// Get all shifts for the week
 	var shifts []models.Shift
-	database.DB.Preload("Officer").
+	database.DB.Preload("Officer", withFormerOfficers).
 		Where("date >= ? AND date <= ?", weekStart, weekEnd).
 		Order("date ASC, shift_type ASC").
 		Find(&shifts)
 
+	leaves, _ := loadApprovedLeaves(database.DB, weekStart, weekEnd)
+	holidays, _ := loadHolidays(database.DB, weekStart, weekEnd)
+	definitions, err := loadShiftDefinitions(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shift definitions"})
+		return
+	}
+
 	// Organize shifts by day
 	dayRotas := make([]DayRota, 7)
 	for i := 0; i < 7; i++ {
@@ -90,8 +110,21 @@ func GetWeekRota(c *gin.Context) {
 		dayRotas[i] = DayRota{
 			Date:       currentDate.Format("2006-01-02"),
 			DayOfWeek:  currentDate.Weekday().String(),
+			Holiday:    holidays[currentDate.Format("2006-01-02")],
+			Shifts:     make([]ShiftDuty, 0, len(definitions)),
 			DayShift:   []OfficerDuty{},
 			NightShift: []OfficerDuty{},
+			OnLeave:    []OfficerDuty{},
+		}
+		for _, d := range definitions {
+			dayRotas[i].Shifts = append(dayRotas[i].Shifts, ShiftDuty{
+				Code:      d.Code,
+				Label:     d.Label,
+				StartTime: d.StartTime,
+				EndTime:   d.EndTime,
+				Colour:    d.Colour,
+				Officers:  []OfficerDuty{},
+			})
 		}
 	}
 
@@ -106,12 +139,28 @@ func GetWeekRota(c *gin.Context) {
 			Name:   shift.Officer.Name,
 			Role:   string(shift.Officer.Role),
 			Status: string(shift.Status),
+			Relief: shift.Relief,
+		}
+
+		if shift.Status == models.StatusOnLeave {
+			if leave := findLeave(leaves, shift.OfficerID, shift.Date); leave != nil {
+				duty.LeaveType = string(leave.Type)
+			}
+			dayRotas[dayIndex].OnLeave = append(dayRotas[dayIndex].OnLeave, duty)
+			continue
 		}
 
-		if shift.ShiftType == models.ShiftDay {
-			dayRotas[dayIndex].DayShift = append(dayRotas[dayIndex].DayShift, duty)
-		} else {
-			dayRotas[dayIndex].NightShift = append(dayRotas[dayIndex].NightShift, duty)
+		day := &dayRotas[dayIndex]
+		for j := range day.Shifts {
+			if day.Shifts[j].Code == shift.ShiftType {
+				day.Shifts[j].Officers = append(day.Shifts[j].Officers, duty)
+			}
+		}
+		switch shift.ShiftType {
+		case models.ShiftDay:
+			day.DayShift = append(day.DayShift, duty)
+		case models.ShiftNight:
+			day.NightShift = append(day.NightShift, duty)
 		}
 	}
 
@@ -119,9 +168,105 @@ func GetWeekRota(c *gin.Context) {
 		WeekStart:      weekStart.Format("2006-01-02"),
 		WeekEnd:        weekEnd.Format("2006-01-02"),
 		DayShiftTeam:   rotation.DayShiftTeam,
-		NightShiftTeam: nightShiftTeam,
+		NightShiftTeam: rotation.NightShiftTeam,
 		Days:           dayRotas,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
+
+// rotaGridDay holds the officer names shown in one day column of the exported rota grid
+type rotaGridDay struct {
+	date    time.Time
+	holiday string                        // public holiday name, if any
+	onDuty  map[models.ShiftType][]string // on duty, by shift
+	dayOff  []string                      // rostered off
+	onLeave []string                      // on approved leave, with leave code
+}
+
+// rotaGridRow is one row of the exported rota grid
+type rotaGridRow struct {
+	label  string
+	times  string // e.g. 06:00-18:00, empty for the day-off and leave rows
+	colour string // #RRGGBB label fill, empty for none
+	names  func(d rotaGridDay) []string
+}
+
+// rotaGridRows lists the rows of the exported rota grid in print order: one per shift definition,
+// then day-off and leave
+func rotaGridRows(definitions []models.ShiftDefinition) []rotaGridRow {
+	rows := make([]rotaGridRow, 0, len(definitions)+2)
+	for _, d := range definitions {
+		code := d.Code
+		rows = append(rows, rotaGridRow{
+			label:  strings.ToUpper(d.Label),
+			times:  d.StartTime + "-" + d.EndTime,
+			colour: d.Colour,
+			names:  func(day rotaGridDay) []string { return day.onDuty[code] },
+		})
+	}
+	return append(rows,
+		rotaGridRow{label: "DAY-OFF", names: func(d rotaGridDay) []string { return d.dayOff }},
+		rotaGridRow{label: "ON LEAVE", names: func(d rotaGridDay) []string { return d.onLeave }},
+	)
+}
+
+// loadRotaGrid organizes a week's shifts into the SHIFT TYPE x day grid used by the PDF and DOCX exports
+func loadRotaGrid(weekStart time.Time) ([]rotaGridDay, []rotaGridRow) {
+	weekEnd := weekStart.AddDate(0, 0, 6)
+
+	var shifts []models.Shift
+	database.DB.Preload("Officer", withFormerOfficers).
+		Where("date >= ? AND date <= ?", weekStart, weekEnd).
+		Order("date ASC, shift_type ASC, officer_id ASC").
+		Find(&shifts)
+
+	leaves, _ := loadApprovedLeaves(database.DB, weekStart, weekEnd)
+	holidays, _ := loadHolidays(database.DB, weekStart, weekEnd)
+
+	days := make([]rotaGridDay, 7)
+	for i := 0; i < 7; i++ {
+		date := weekStart.AddDate(0, 0, i)
+		days[i] = rotaGridDay{
+			date:    date,
+			holiday: holidays[date.Format("2006-01-02")],
+			onDuty:  make(map[models.ShiftType][]string),
+		}
+	}
+
+	definitions, _ := loadShiftDefinitions(database.DB)
+
+	for _, shift := range shifts {
+		dayIndex := int(shift.Date.Sub(weekStart).Hours() / 24)
+		if dayIndex < 0 || dayIndex > 6 {
+			continue
+		}
+
+		// Acting sergeants supervising a shift are marked A/SGT
+		name := rotaDisplayName(shift.Officer.Name)
+		if shift.Relief && shift.Status == models.StatusOnDuty {
+			name += " (A/SGT)"
+		}
+
+		switch {
+		case shift.Status == models.StatusOnLeave:
+			if leave := findLeave(leaves, shift.OfficerID, shift.Date); leave != nil {
+				name = fmt.Sprintf("%s (%s)", name, leaveCode(leave.Type))
+			}
+			days[dayIndex].onLeave = append(days[dayIndex].onLeave, name)
+		case shift.Status == models.StatusOffDuty:
+			days[dayIndex].dayOff = append(days[dayIndex].dayOff, name)
+		default:
+			days[dayIndex].onDuty[shift.ShiftType] = append(days[dayIndex].onDuty[shift.ShiftType], name)
+		}
+	}
+
+	return days, rotaGridRows(definitions)
+}
+
+// rotaDisplayName formats an officer name for the printed rota (prefixes removed, uppercase)
+func rotaDisplayName(name string) string {
+	name = strings.TrimPrefix(name, "Officer ")
+	name = strings.TrimPrefix(name, "Sgt. ")
+	return strings.ToUpper(name)
+}
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/unidoc/unioffice/color"
+	"github.com/unidoc/unioffice/measurement"
+	"github.com/unidoc/unioffice/schema/soo/sml"
+	"github.com/unidoc/unioffice/spreadsheet"
+)
+
+// GetWeekRotaXLSX godoc
+// @Summary Download weekly rota as XLSX
+// @Description Generate and download an Excel workbook of the weekly duty rota: a formatted rota sheet laid out
+// @Description like the PDF and a data sheet with one filterable row per shift
+// @Tags rota
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param week_start query string true "Week start date (Sunday, YYYY-MM-DD)"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /rota/week/xlsx [get]
+func GetWeekRotaXLSX(c *gin.Context) {
+	weekStartStr := c.Query("week_start")
+	if weekStartStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "week_start is required"})
+		return
+	}
+
+	weekStart, err := time.Parse("2006-01-02", weekStartStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+		return
+	}
+
+	if weekStart.Weekday() != time.Sunday {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "week_start must be a Sunday"})
+		return
+	}
+
+	// Get week rotation info
+	var rotation models.WeekRotation
+	if err := database.DB.Where("week_start = ?", weekStart).First(&rotation).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No rota found for this week"})
+		return
+	}
+
+	days, rows := loadRotaGrid(weekStart)
+
+	var shifts []models.Shift
+	database.DB.Preload("Officer", withFormerOfficers).
+		Where("date >= ? AND date <= ?", weekStart, weekStart.AddDate(0, 0, 6)).
+		Order("date ASC, shift_type ASC, officer_id ASC").
+		Find(&shifts)
+
+	wb := spreadsheet.New()
+	styles := newXLSXStyles(wb)
+
+	// Rota sheet: SHIFT TYPE x day, as printed
+	sheet := wb.AddSheet()
+	sheet.SetName("Rota")
+	sheet.Column(1).SetWidth(16 * measurement.Character)
+	for i := range days {
+		sheet.Column(uint32(i + 2)).SetWidth(24 * measurement.Character)
+	}
+
+	row := sheet.AddRow()
+	styles.cell(row.AddCell(), "SHIFT TYPE", styles.header)
+	for _, d := range days {
+		text := strings.ToUpper(d.date.Weekday().String()) + "\n" + d.date.Format("02/01/06")
+		style := styles.header
+		if d.holiday != "" {
+			text += "\n" + strings.ToUpper(d.holiday)
+			style = styles.fill("#FFCCCC", true)
+		}
+		styles.cell(row.AddCell(), text, style)
+	}
+
+	for _, r := range rows {
+		row = sheet.AddRow()
+		label := r.label
+		if r.times != "" {
+			label += "\n" + r.times
+		}
+		styles.cell(row.AddCell(), label, styles.fill(r.colour, true))
+		for _, d := range days {
+			styles.cell(row.AddCell(), strings.Join(r.names(d), "\n"), styles.text)
+		}
+	}
+	sheet.SetFrozen(true, true)
+
+	addShiftDataSheet(wb, styles, shifts)
+
+	writeXLSX(c, wb, fmt.Sprintf("rota_%s.xlsx", weekStartStr))
+}
+
+// maxRotaSheetDays is the longest span of dates the shift export lays out as an officer-by-day Rota sheet
+const maxRotaSheetDays = 93
+
+// ExportShiftsXLSX godoc
+// @Summary Export shifts as XLSX
+// @Description Export the shifts matching the GetShifts filters as an Excel workbook: an officer-by-day rota sheet
+// @Description (when the shifts span at most 93 days) and a data sheet with one filterable row per shift
+// @Tags shifts
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param date query string false "Date (YYYY-MM-DD)"
+// @Param officer_id query int false "Officer ID"
+// @Param week_start query string false "Week start date (Sunday, YYYY-MM-DD)"
+// @Success 200 {file} file
+// @Router /shifts/export.xlsx [get]
+func ExportShiftsXLSX(c *gin.Context) {
+	var input GetShiftsInput
+	c.ShouldBindQuery(&input)
+
+	var shifts []models.Shift
+	shiftsQuery(input).Order("date ASC, shift_type ASC, officer_id ASC").Find(&shifts)
+
+	wb := spreadsheet.New()
+	styles := newXLSXStyles(wb)
+
+	// Rota sheet: officer x day across the dates exported, with totals. Longer spans, such as an officer's
+	// whole history, only get the data sheet.
+	if len(shifts) > 0 && shifts[len(shifts)-1].Date.Sub(shifts[0].Date) < maxRotaSheetDays*24*time.Hour {
+		grid := loadOfficerGrid(shifts[0].Date, shifts[len(shifts)-1].Date, shifts)
+
+		sheet := wb.AddSheet()
+		sheet.SetName("Rota")
+		sheet.Column(1).SetWidth(24 * measurement.Character)
+		for i := 0; i < len(grid.days)+len(grid.definitions)+3; i++ {
+			sheet.Column(uint32(i + 2)).SetWidth(7 * measurement.Character)
+		}
+
+		row := sheet.AddRow()
+		styles.cell(row.AddCell(), "OFFICER", styles.header)
+		for _, d := range grid.days {
+			style := styles.header
+			if grid.holidays[d.Format("2006-01-02")] != "" {
+				style = styles.fill("#FFCCCC", true)
+			}
+			styles.cell(row.AddCell(), d.Format("Mon\n02/01"), style)
+		}
+		for _, d := range grid.definitions {
+			styles.cell(row.AddCell(), grid.abbreviations[d.Code], styles.header)
+		}
+		styles.cell(row.AddCell(), "OFF", styles.header)
+		styles.cell(row.AddCell(), "LV", styles.header)
+		styles.cell(row.AddCell(), "HRS", styles.header)
+
+		for _, officer := range grid.officers {
+			row = sheet.AddRow()
+			styles.cell(row.AddCell(), officer.name, styles.text)
+			for _, day := range officer.cells {
+				styles.cell(row.AddCell(), day.code, styles.fill(day.colour, false))
+			}
+			for _, d := range grid.definitions {
+				styles.number(row.AddCell(), float64(officer.shifts[d.Code]))
+			}
+			styles.number(row.AddCell(), float64(officer.off))
+			styles.number(row.AddCell(), float64(officer.leave))
+			styles.number(row.AddCell(), officer.hours)
+		}
+		sheet.SetFrozen(true, true)
+	}
+
+	addShiftDataSheet(wb, styles, shifts)
+
+	writeXLSX(c, wb, "shifts.xlsx")
+}
+
+// shiftDataColumns are the columns of the shift data sheet
+var shiftDataColumns = []struct {
+	header string
+	width  float64
+}{
+	{"Date", 12}, {"Day", 11}, {"Officer", 24}, {"Badge No", 10}, {"Team", 6}, {"Role", 10},
+	{"Shift Code", 10}, {"Shift", 14}, {"Start", 7}, {"End", 7}, {"Hours", 7},
+	{"Status", 10}, {"Leave Type", 10}, {"Relief", 7}, {"Manual", 7},
+}
+
+// addShiftDataSheet adds a sheet with one row per shift, a frozen header and filters on every column.
+// Shifts must have their Officer loaded.
+func addShiftDataSheet(wb *spreadsheet.Workbook, styles *xlsxStyles, shifts []models.Shift) {
+	sheet := wb.AddSheet()
+	sheet.SetName("Shifts")
+
+	row := sheet.AddRow()
+	for i, column := range shiftDataColumns {
+		sheet.Column(uint32(i + 1)).SetWidth(measurement.Distance(column.width) * measurement.Character)
+		styles.cell(row.AddCell(), column.header, styles.header)
+	}
+
+	definitions, _ := loadShiftDefinitions(database.DB)
+	var leaves []models.Leave
+	if len(shifts) > 0 {
+		leaves, _ = loadApprovedLeaves(database.DB, shifts[0].Date, shifts[len(shifts)-1].Date)
+	}
+
+	yesNo := map[bool]string{true: "Yes", false: "No"}
+	for _, shift := range shifts {
+		row = sheet.AddRow()
+		row.AddCell().SetDateWithStyle(shift.Date)
+		row.AddCell().SetString(shift.Date.Weekday().String())
+		row.AddCell().SetString(shift.Officer.Name)
+		badgeNo := ""
+		if shift.Officer.BadgeNo != nil {
+			badgeNo = *shift.Officer.BadgeNo
+		}
+		row.AddCell().SetString(badgeNo)
+		row.AddCell().SetNumber(float64(shift.Officer.Team))
+		row.AddCell().SetString(string(shift.Officer.Role))
+		row.AddCell().SetString(string(shift.ShiftType))
+		if definition := findShiftDefinition(definitions, shift.ShiftType); definition != nil {
+			row.AddCell().SetString(definition.Label)
+			row.AddCell().SetString(definition.StartTime)
+			row.AddCell().SetString(definition.EndTime)
+			if shift.Status == models.StatusOnDuty {
+				row.AddCell().SetNumber(definition.Hours())
+			} else {
+				row.AddCell().SetNumber(0)
+			}
+		} else {
+			row.AddCell().SetString(string(shift.ShiftType))
+			row.AddCell()
+			row.AddCell()
+			row.AddCell().SetNumber(0)
+		}
+		row.AddCell().SetString(string(shift.Status))
+		leaveType := ""
+		if shift.Status == models.StatusOnLeave {
+			if leave := findLeave(leaves, shift.OfficerID, shift.Date); leave != nil {
+				leaveType = string(leave.Type)
+			}
+		}
+		row.AddCell().SetString(leaveType)
+		row.AddCell().SetString(yesNo[shift.Relief])
+		row.AddCell().SetString(yesNo[shift.Manual])
+	}
+
+	lastColumn := string(rune('A' + len(shiftDataColumns) - 1))
+	sheet.SetAutoFilter(fmt.Sprintf("A1:%s%d", lastColumn, len(shifts)+1))
+	sheet.SetFrozen(true, false)
+}
+
+// xlsxStyles holds the cell styles shared by a workbook's sheets
+type xlsxStyles struct {
+	wb     *spreadsheet.Workbook
+	border spreadsheet.Border
+	header spreadsheet.CellStyle
+	text   spreadsheet.CellStyle
+	fills  map[string]spreadsheet.CellStyle
+}
+
+func newXLSXStyles(wb *spreadsheet.Workbook) *xlsxStyles {
+	s := &xlsxStyles{wb: wb, fills: make(map[string]spreadsheet.CellStyle)}
+
+	s.border = wb.StyleSheet.AddBorder()
+	s.border.SetLeft(sml.ST_BorderStyleThin, color.Black)
+	s.border.SetRight(sml.ST_BorderStyleThin, color.Black)
+	s.border.SetTop(sml.ST_BorderStyleThin, color.Black)
+	s.border.SetBottom(sml.ST_BorderStyleThin, color.Black)
+
+	s.header = s.fill("#C0C0C0", true)
+	s.text = wb.StyleSheet.AddCellStyle()
+	s.text.SetBorder(s.border)
+	s.text.SetWrapped(true)
+	s.text.SetVerticalAlignment(sml.ST_VerticalAlignmentTop)
+	return s
+}
+
+// fill returns a bordered, centred style filled in a #RRGGBB colour (none if empty), bold if asked
+func (s *xlsxStyles) fill(hex string, bold bool) spreadsheet.CellStyle {
+	key := fmt.Sprintf("%s/%t", hex, bold)
+	if style, ok := s.fills[key]; ok {
+		return style
+	}
+
+	style := s.wb.StyleSheet.AddCellStyle()
+	style.SetBorder(s.border)
+	style.SetWrapped(true)
+	style.SetHorizontalAlignment(sml.ST_HorizontalAlignmentCenter)
+	style.SetVerticalAlignment(sml.ST_VerticalAlignmentTop)
+	if hex != "" {
+		fill := s.wb.StyleSheet.Fills().AddFill()
+		pattern := fill.SetPatternFill()
+		pattern.SetPattern(sml.ST_PatternTypeSolid)
+		pattern.SetFgColor(color.FromHex(hex))
+		style.SetFill(fill)
+	}
+	if bold {
+		font := s.wb.StyleSheet.AddFont()
+		font.SetBold(true)
+		style.SetFont(font)
+	}
+	s.fills[key] = style
+	return style
+}
+
+func (s *xlsxStyles) cell(cell spreadsheet.Cell, text string, style spreadsheet.CellStyle) {
+	cell.SetString(text)
+	cell.SetStyle(style)
+}
+
+func (s *xlsxStyles) number(cell spreadsheet.Cell, value float64) {
+	cell.SetNumber(value)
+	cell.SetStyle(s.fill("", true))
+}
+
+func writeXLSX(c *gin.Context, wb *spreadsheet.Workbook, filename string) {
+	var buf bytes.Buffer
+	if err := wb.Save(&buf); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate XLSX"})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buf.Bytes())
+}
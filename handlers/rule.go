@@ -0,0 +1,368 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ShiftTemplateInput represents a fixed weekly pattern in a rule set
+type ShiftTemplateInput struct {
+	Name      string               `json:"name"`
+	OfficerID *uint                `json:"officer_id"`
+	Role      models.OfficerRole   `json:"role" binding:"omitempty,oneof=sergeant regular"`
+	Gender    models.OfficerGender `json:"gender" binding:"omitempty,oneof=female male"`
+	Position  *int                 `json:"position" binding:"omitempty,min=0"`
+	ShiftType models.ShiftType     `json:"shift_type" binding:"required"`
+	DaysOff   string               `json:"days_off"` // e.g. "sat" or "sun,sat"
+	SortOrder int                  `json:"sort_order"`
+}
+
+// StaffingRuleInput represents per-weekday or public holiday staffing for a team shift
+type StaffingRuleInput struct {
+	Weekday   int              `json:"weekday" binding:"min=0,max=6"`
+	Holiday   bool             `json:"holiday"` // Use on public holidays instead of a weekday
+	ShiftType models.ShiftType `json:"shift_type" binding:"required"`
+	MaxOnDuty int              `json:"max_on_duty" binding:"min=0"`
+	OffCount  int              `json:"off_count" binding:"min=0"`
+}
+
+// CoverageRuleInput represents the minimum staffing and mix of officers on a shift
+type CoverageRuleInput struct {
+	Weekday           *int             `json:"weekday" binding:"omitempty,min=0,max=6"` // Omit for every day
+	ShiftType         models.ShiftType `json:"shift_type" binding:"required"`
+	MinOnDuty         int              `json:"min_on_duty" binding:"min=0"`
+	RequireSergeant   bool             `json:"require_sergeant"`
+	RequireSupervisor bool             `json:"require_supervisor"` // Sergeant or acting sergeant
+	MinFemale         int              `json:"min_female" binding:"min=0"`
+}
+
+// RuleSetInput represents a complete rule set; templates, staffing and coverage replace any existing ones
+type RuleSetInput struct {
+	Name        string               `json:"name" binding:"required"`
+	Description string               `json:"description"`
+	Templates   []ShiftTemplateInput `json:"templates" binding:"dive"`
+	Staffing    []StaffingRuleInput  `json:"staffing" binding:"dive"`
+	Coverage    []CoverageRuleInput  `json:"coverage" binding:"dive"`
+}
+
+// GetRuleSets godoc
+// @Summary Get rotation rule sets
+// @Description Get all rotation rule sets with their templates, staffing and coverage rules
+// @Tags rules
+// @Produce json
+// @Success 200 {array} models.RuleSet
+// @Router /rules [get]
+func GetRuleSets(c *gin.Context) {
+	var ruleSets []models.RuleSet
+	database.DB.Preload("Templates").Preload("Staffing").Preload("Coverage").Order("id ASC").Find(&ruleSets)
+	c.JSON(http.StatusOK, ruleSets)
+}
+
+// GetRuleSet godoc
+// @Summary Get a rotation rule set by ID
+// @Description Get a single rotation rule set with its templates, staffing and coverage rules
+// @Tags rules
+// @Produce json
+// @Param id path int true "Rule set ID"
+// @Success 200 {object} models.RuleSet
+// @Failure 404 {object} map[string]string
+// @Router /rules/{id} [get]
+func GetRuleSet(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var ruleSet models.RuleSet
+	if err := database.DB.Preload("Templates").Preload("Staffing").Preload("Coverage").First(&ruleSet, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rule set not found"})
+		return
+	}
+	c.JSON(http.StatusOK, ruleSet)
+}
+
+// CreateRuleSet godoc
+// @Summary Create a rotation rule set
+// @Description Create an inactive rotation rule set. Activate it to use it for generation.
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param input body RuleSetInput true "Rule set"
+// @Success 201 {object} models.RuleSet
+// @Failure 400 {object} map[string]string
+// @Router /rules [post]
+func CreateRuleSet(c *gin.Context) {
+	var input RuleSetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateRuleSetInput(database.DB, input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ruleSet := models.RuleSet{
+		Name:        input.Name,
+		Description: input.Description,
+		Templates:   input.templates(),
+		Staffing:    input.staffing(),
+		Coverage:    input.coverage(),
+	}
+
+	if err := database.DB.Create(&ruleSet).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create rule set (duplicate name?)"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, ruleSet)
+}
+
+// UpdateRuleSet godoc
+// @Summary Update a rotation rule set
+// @Description Replace a rule set's name, description, templates, staffing and coverage rules
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param id path int true "Rule set ID"
+// @Param input body RuleSetInput true "Rule set"
+// @Success 200 {object} models.RuleSet
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /rules/{id} [put]
+func UpdateRuleSet(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var ruleSet models.RuleSet
+	if err := database.DB.First(&ruleSet, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rule set not found"})
+		return
+	}
+
+	var input RuleSetInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := validateRuleSetInput(database.DB, input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&ruleSet).Updates(map[string]interface{}{
+			"name":        input.Name,
+			"description": input.Description,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("rule_set_id = ?", ruleSet.ID).Delete(&models.ShiftTemplate{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("rule_set_id = ?", ruleSet.ID).Delete(&models.StaffingRule{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("rule_set_id = ?", ruleSet.ID).Delete(&models.CoverageRule{}).Error; err != nil {
+			return err
+		}
+
+		templates := input.templates()
+		for i := range templates {
+			templates[i].RuleSetID = ruleSet.ID
+		}
+		if len(templates) > 0 {
+			if err := tx.Create(&templates).Error; err != nil {
+				return err
+			}
+		}
+
+		staffing := input.staffing()
+		for i := range staffing {
+			staffing[i].RuleSetID = ruleSet.ID
+		}
+		if len(staffing) > 0 {
+			if err := tx.Create(&staffing).Error; err != nil {
+				return err
+			}
+		}
+
+		coverage := input.coverage()
+		for i := range coverage {
+			coverage[i].RuleSetID = ruleSet.ID
+		}
+		if len(coverage) > 0 {
+			if err := tx.Create(&coverage).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update rule set: " + err.Error()})
+		return
+	}
+
+	database.DB.Preload("Templates").Preload("Staffing").Preload("Coverage").First(&ruleSet, ruleSet.ID)
+	c.JSON(http.StatusOK, ruleSet)
+}
+
+// DeleteRuleSet godoc
+// @Summary Delete a rotation rule set
+// @Description Delete an inactive rotation rule set
+// @Tags rules
+// @Param id path int true "Rule set ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /rules/{id} [delete]
+func DeleteRuleSet(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var ruleSet models.RuleSet
+	if err := database.DB.First(&ruleSet, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rule set not found"})
+		return
+	}
+
+	if ruleSet.Active {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The active rule set cannot be deleted; activate another one first"})
+		return
+	}
+
+	database.DB.Select("Templates", "Staffing", "Coverage").Delete(&ruleSet)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// ActivateRuleSet godoc
+// @Summary Activate a rotation rule set
+// @Description Make this rule set the one used by rota generation
+// @Tags rules
+// @Produce json
+// @Param id path int true "Rule set ID"
+// @Success 200 {object} models.RuleSet
+// @Failure 404 {object} map[string]string
+// @Router /rules/{id}/activate [post]
+func ActivateRuleSet(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var ruleSet models.RuleSet
+	if err := database.DB.First(&ruleSet, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rule set not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.RuleSet{}).Where("active = ?", true).Update("active", false).Error; err != nil {
+			return err
+		}
+		return tx.Model(&ruleSet).Update("active", true).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to activate rule set"})
+		return
+	}
+
+	c.JSON(http.StatusOK, ruleSet)
+}
+
+// validateRuleSetInput checks rules that binding tags cannot express
+func validateRuleSetInput(db *gorm.DB, input RuleSetInput) error {
+	var shiftTypes []models.ShiftType
+	for _, t := range input.Templates {
+		shiftTypes = append(shiftTypes, t.ShiftType)
+	}
+	for _, s := range input.Staffing {
+		shiftTypes = append(shiftTypes, s.ShiftType)
+	}
+	for _, r := range input.Coverage {
+		shiftTypes = append(shiftTypes, r.ShiftType)
+	}
+	if err := validateShiftTypes(db, shiftTypes...); err != nil {
+		return err
+	}
+
+	for i, t := range input.Templates {
+		if t.OfficerID == nil && t.Role == "" && t.Gender == "" {
+			return fmt.Errorf("template %d: officer_id, role or gender is required", i+1)
+		}
+		if _, err := models.ParseWeekdays(t.DaysOff); err != nil {
+			return fmt.Errorf("template %d: %v", i+1, err)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, s := range input.Staffing {
+		key := fmt.Sprintf("%d/%s", s.Weekday, s.ShiftType)
+		if s.Holiday {
+			key = "holiday/" + string(s.ShiftType)
+		}
+		if seen[key] {
+			if s.Holiday {
+				return fmt.Errorf("staffing rule %d: duplicate holiday rule for %s shift", i+1, s.ShiftType)
+			}
+			return fmt.Errorf("staffing rule %d: duplicate rule for weekday %d %s shift", i+1, s.Weekday, s.ShiftType)
+		}
+		seen[key] = true
+	}
+
+	seen = make(map[string]bool)
+	for i, r := range input.Coverage {
+		key := "every/" + string(r.ShiftType)
+		if r.Weekday != nil {
+			key = fmt.Sprintf("%d/%s", *r.Weekday, r.ShiftType)
+		}
+		if seen[key] {
+			return fmt.Errorf("coverage rule %d: duplicate rule for the same weekday and %s shift", i+1, r.ShiftType)
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+func (input RuleSetInput) templates() []models.ShiftTemplate {
+	templates := make([]models.ShiftTemplate, 0, len(input.Templates))
+	for _, t := range input.Templates {
+		templates = append(templates, models.ShiftTemplate{
+			Name:      t.Name,
+			OfficerID: t.OfficerID,
+			Role:      t.Role,
+			Gender:    t.Gender,
+			Position:  t.Position,
+			ShiftType: t.ShiftType,
+			DaysOff:   t.DaysOff,
+			SortOrder: t.SortOrder,
+		})
+	}
+	return templates
+}
+
+func (input RuleSetInput) staffing() []models.StaffingRule {
+	staffing := make([]models.StaffingRule, 0, len(input.Staffing))
+	for _, s := range input.Staffing {
+		staffing = append(staffing, models.StaffingRule{
+			Weekday:   s.Weekday,
+			Holiday:   s.Holiday,
+			ShiftType: s.ShiftType,
+			MaxOnDuty: s.MaxOnDuty,
+			OffCount:  s.OffCount,
+		})
+	}
+	return staffing
+}
+
+func (input RuleSetInput) coverage() []models.CoverageRule {
+	coverage := make([]models.CoverageRule, 0, len(input.Coverage))
+	for _, r := range input.Coverage {
+		coverage = append(coverage, models.CoverageRule{
+			Weekday:           r.Weekday,
+			ShiftType:         r.ShiftType,
+			MinOnDuty:         r.MinOnDuty,
+			RequireSergeant:   r.RequireSergeant,
+			RequireSupervisor: r.RequireSupervisor,
+			MinFemale:         r.MinFemale,
+		})
+	}
+	return coverage
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/robfig/cron/v3"
+)
+
+// Scheduler keeps upcoming weeks generated in the background
+type Scheduler struct {
+	cron    *cron.Cron
+	horizon int
+}
+
+// StartScheduler starts background generation when enabled.
+// AUTO_GENERATE_WEEKS sets how many upcoming weeks to keep generated (0, the default, disables it) and
+// AUTO_GENERATE_SCHEDULE is a cron expression for when to check (default daily at 02:00).
+func StartScheduler() (*Scheduler, error) {
+	horizon, err := strconv.Atoi(database.GetEnv("AUTO_GENERATE_WEEKS", "0"))
+	if err != nil || horizon < 0 || horizon > maxRangeWeeks {
+		return nil, fmt.Errorf("invalid AUTO_GENERATE_WEEKS, expected 0 to %d", maxRangeWeeks)
+	}
+	if horizon == 0 {
+		log.Println("Auto-generation disabled")
+		return nil, nil
+	}
+
+	schedule := database.GetEnv("AUTO_GENERATE_SCHEDULE", "0 2 * * *")
+	// Runs never overlap, and a panic in one run does not stop the schedule
+	c := cron.New(cron.WithChain(cron.Recover(cron.DefaultLogger), cron.SkipIfStillRunning(cron.DefaultLogger)))
+	s := &Scheduler{cron: c, horizon: horizon}
+	if _, err := s.cron.AddFunc(schedule, s.run); err != nil {
+		return nil, err
+	}
+	s.cron.Start()
+
+	log.Printf("Auto-generation keeps %d weeks ahead on schedule %q", horizon, schedule)
+	return s, nil
+}
+
+// Stop stops the schedule and waits for a run in progress to finish or ctx to expire
+func (s *Scheduler) Stop(ctx context.Context) {
+	if s == nil {
+		return
+	}
+	select {
+	case <-s.cron.Stop().Done():
+	case <-ctx.Done():
+		log.Println("Auto-generation run still in progress at shutdown")
+	}
+}
+
+// run generates any missing weeks from next Sunday up to the horizon and records the outcome
+func (s *Scheduler) run() {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	fromWeek := today.AddDate(0, 0, 7-int(today.Weekday()))
+	toWeek := fromWeek.AddDate(0, 0, 7*(s.horizon-1))
+
+	run := models.GenerationRun{
+		StartedAt: now,
+		Status:    models.RunRunning,
+		FromWeek:  fromWeek,
+		ToWeek:    toWeek,
+	}
+	if err := database.DB.Create(&run).Error; err != nil {
+		log.Println("Auto-generation: failed to record run:", err)
+		return
+	}
+
+	// cron.Recover keeps the schedule going after a panic, but the run would stay "running"
+	defer func() {
+		if r := recover(); r != nil {
+			database.DB.Model(&run).Updates(map[string]interface{}{
+				"finished_at": time.Now(),
+				"status":      models.RunFailed,
+				"error":       fmt.Sprint("panic: ", r),
+			})
+			panic(r)
+		}
+	}()
+
+	weeks, generated, skipped, err := generateRange(database.DB, fromWeek, toWeek, generateOptions{})
+
+	shortfalls := 0
+	for _, week := range weeks {
+		shortfalls += len(week.Shortfalls)
+	}
+
+	finished := time.Now()
+	updates := map[string]interface{}{
+		"finished_at":     finished,
+		"status":          models.RunSucceeded,
+		"weeks_generated": generated,
+		"weeks_skipped":   skipped,
+		"shortfalls":      shortfalls,
+	}
+	if err != nil {
+		updates["status"] = models.RunFailed
+		updates["error"] = err.Error()
+		log.Println("Auto-generation failed:", err)
+	} else if generated > 0 {
+		log.Printf("Auto-generation: generated %d weeks with %d coverage shortfalls", generated, shortfalls)
+	}
+	database.DB.Model(&run).Updates(updates)
+}
+
+// GetGenerationRuns godoc
+// @Summary Get auto-generation runs
+// @Description Get the most recent background rota generation runs, newest first
+// @Tags admin
+// @Produce json
+// @Param limit query int false "Maximum runs to return (default 50)"
+// @Success 200 {array} models.GenerationRun
+// @Router /admin/generation-runs [get]
+func GetGenerationRuns(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 {
+		limit = 50
+	}
+
+	var runs []models.GenerationRun
+	database.DB.Order("started_at DESC").Limit(limit).Find(&runs)
+	c.JSON(http.StatusOK, runs)
+}
@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"securityrota-api/database"
 	"securityrota-api/models"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
 // GetShiftsInput represents query params for getting shifts
@@ -31,7 +35,14 @@ func GetShifts(c *gin.Context) {
 	var input GetShiftsInput
 	c.ShouldBindQuery(&input)
 
-	query := database.DB.Preload("Officer")
+	var shifts []models.Shift
+	shiftsQuery(input).Order("date ASC").Find(&shifts)
+	c.JSON(http.StatusOK, shifts)
+}
+
+// shiftsQuery applies the GetShifts filters, with each shift's officer preloaded
+func shiftsQuery(input GetShiftsInput) *gorm.DB {
+	query := database.DB.Preload("Officer", withFormerOfficers)
 
 	if input.Date != "" {
 		date, _ := time.Parse("2006-01-02", input.Date)
@@ -47,10 +58,7 @@ func GetShifts(c *gin.Context) {
 		weekEnd := weekStart.AddDate(0, 0, 7)
 		query = query.Where("date >= ? AND date < ?", weekStart, weekEnd)
 	}
-
-	var shifts []models.Shift
-	query.Order("date ASC").Find(&shifts)
-	c.JSON(http.StatusOK, shifts)
+	return query
 }
 
 // GenerateWeekRotaInput represents input for generating a week's rota
@@ -60,13 +68,22 @@ type GenerateWeekRotaInput struct {
 
 // GenerateWeekRota godoc
 // @Summary Generate rota for a week
-// @Description Generate the complete shift rota for a given week starting on Sunday
+// @Description Generate the complete shift rota for a given week starting on Sunday.
+// @Description dry_run=true returns the proposed shifts without saving them.
+// @Description mode=regenerate replaces an existing week, keeping manually edited and on-leave shifts.
+// @Description Coverage shortfalls are returned as warnings; strict=true refuses to save a week that has any.
 // @Tags shifts
 // @Accept json
 // @Produce json
 // @Param input body GenerateWeekRotaInput true "Week start date (must be Sunday)"
+// @Param dry_run query bool false "Preview without saving"
+// @Param mode query string false "regenerate to replace an existing week"
+// @Param strict query bool false "Refuse a rota that breaks a coverage rule"
+// @Success 200 {object} map[string]interface{}
 // @Success 201 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
 // @Router /shifts/generate [post]
 func GenerateWeekRota(c *gin.Context) {
 	var input GenerateWeekRotaInput
@@ -86,223 +103,194 @@ func GenerateWeekRota(c *gin.Context) {
 		return
 	}
 
-	// Check if rota already exists for this week
-	var existingRotation models.WeekRotation
-	if database.DB.Where("week_start = ?", weekStart).First(&existingRotation).Error == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Rota already exists for this week"})
+	opts := generateOptions{
+		DryRun:     c.Query("dry_run") == "true",
+		Regenerate: c.Query("mode") == "regenerate",
+		Strict:     c.Query("strict") == "true",
+	}
+
+	result, err := generateWeek(database.DB, weekStart, opts)
+	if err != nil {
+		c.JSON(generationErrorResponse(err))
 		return
 	}
 
-	// Get previous week's rotation to determine this week's teams
-	prevWeekStart := weekStart.AddDate(0, 0, -7)
-	var prevRotation models.WeekRotation
-	dayShiftTeam := 1 // Default if no previous rotation
-	if database.DB.Where("week_start = ?", prevWeekStart).First(&prevRotation).Error == nil {
-		// Swap teams: previous day shift becomes night shift
-		if prevRotation.DayShiftTeam == 1 {
-			dayShiftTeam = 2
-		} else {
-			dayShiftTeam = 1
-		}
+	status := http.StatusCreated
+	message := "Rota generated successfully"
+	if opts.DryRun {
+		status = http.StatusOK
+		message = "Dry run: rota not saved"
+	} else if opts.Regenerate {
+		message = "Rota regenerated successfully"
 	}
 
-	nightShiftTeam := 1
-	if dayShiftTeam == 1 {
-		nightShiftTeam = 2
+	response := gin.H{
+		"message":          message,
+		"week_start":       result.WeekStart,
+		"day_shift_team":   result.DayShiftTeam,
+		"night_shift_team": result.NightShiftTeam,
+		"rest_teams":       result.RestTeams,
+		"cycle_week":       result.CycleWeek,
+		"rule_set":         result.RuleSet,
+		"shifts_created":   result.ShiftsCreated,
+		"shifts_kept":      result.ShiftsKept,
+		"shortfalls":       result.Shortfalls,
+	}
+	if opts.DryRun {
+		response["shifts"] = result.Shifts
 	}
 
-	// Create week rotation record
-	rotation := models.WeekRotation{
-		WeekStart:    weekStart,
-		DayShiftTeam: dayShiftTeam,
+	c.JSON(status, response)
+}
+
+// GenerateRangeRotaInput represents input for generating every week between two Sundays
+type GenerateRangeRotaInput struct {
+	StartWeek string `json:"start_week" binding:"required"` // YYYY-MM-DD (must be Sunday)
+	EndWeek   string `json:"end_week" binding:"required"`   // YYYY-MM-DD (must be Sunday, inclusive)
+}
+
+// maxRangeWeeks caps how many weeks one range request can generate
+const maxRangeWeeks = 53
+
+// GenerateRangeRota godoc
+// @Summary Generate rota for a range of weeks
+// @Description Generate every week from start_week to end_week (inclusive, both Sundays) in one request.
+// @Description The team rotation continues from the latest existing week; weeks that already have a rota are skipped.
+// @Description dry_run=true returns the proposed shifts without saving them.
+// @Description strict=true refuses the whole range if any generated week breaks a coverage rule.
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param input body GenerateRangeRotaInput true "First and last week start dates (Sundays)"
+// @Param dry_run query bool false "Preview without saving"
+// @Param strict query bool false "Refuse a rota that breaks a coverage rule"
+// @Success 200 {object} map[string]interface{}
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 422 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /shifts/generate-range [post]
+func GenerateRangeRota(c *gin.Context) {
+	var input GenerateRangeRotaInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
-	database.DB.Create(&rotation)
 
-	// Get all officers
-	var sergeant models.Officer
-	var female1, female2 models.Officer
-	var dayTeamOfficers, nightTeamOfficers []models.Officer
+	startWeek, err := time.Parse("2006-01-02", input.StartWeek)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start_week format, use YYYY-MM-DD"})
+		return
+	}
 
-	database.DB.Where("role = ?", models.RoleSergeant).First(&sergeant)
-	database.DB.Where("role = ?", models.RoleFemale).Find(&[]models.Officer{})
+	endWeek, err := time.Parse("2006-01-02", input.EndWeek)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end_week format, use YYYY-MM-DD"})
+		return
+	}
 
-	// Get female officers (assuming we have exactly 2)
-	var females []models.Officer
-	database.DB.Where("role = ?", models.RoleFemale).Find(&females)
-	if len(females) >= 2 {
-		female1 = females[0] // Sunday off
-		female2 = females[1] // Saturday off
+	if startWeek.Weekday() != time.Sunday || endWeek.Weekday() != time.Sunday {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_week and end_week must be Sundays"})
+		return
 	}
 
-	database.DB.Where("role = ? AND team = ?", models.RoleRegular, dayShiftTeam).Find(&dayTeamOfficers)
-	database.DB.Where("role = ? AND team = ?", models.RoleRegular, nightShiftTeam).Find(&nightTeamOfficers)
+	if endWeek.Before(startWeek) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "end_week must not be before start_week"})
+		return
+	}
 
-	var shifts []models.Shift
+	if weeksBetween(startWeek, endWeek) >= maxRangeWeeks {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A range can cover at most %d weeks", maxRangeWeeks)})
+		return
+	}
 
-	// Generate shifts for each day of the week
-	for dayOffset := 0; dayOffset < 7; dayOffset++ {
-		currentDate := weekStart.AddDate(0, 0, dayOffset)
-		weekday := currentDate.Weekday()
-
-		// Sergeant: Day shift Sun-Fri, off Saturday
-		if sergeant.ID > 0 {
-			if weekday != time.Saturday {
-				shifts = append(shifts, models.Shift{
-					OfficerID: sergeant.ID,
-					Date:      currentDate,
-					ShiftType: models.ShiftDay,
-					Status:    models.StatusOnDuty,
-				})
-			} else {
-				shifts = append(shifts, models.Shift{
-					OfficerID: sergeant.ID,
-					Date:      currentDate,
-					ShiftType: models.ShiftDay,
-					Status:    models.StatusOffDuty,
-				})
-			}
-		}
+	opts := generateOptions{
+		DryRun: c.Query("dry_run") == "true",
+		Strict: c.Query("strict") == "true",
+	}
 
-		// Female Officer 1: Day shift Mon-Sat, off Sunday
-		if female1.ID > 0 {
-			if weekday != time.Sunday {
-				shifts = append(shifts, models.Shift{
-					OfficerID: female1.ID,
-					Date:      currentDate,
-					ShiftType: models.ShiftDay,
-					Status:    models.StatusOnDuty,
-				})
-			} else {
-				shifts = append(shifts, models.Shift{
-					OfficerID: female1.ID,
-					Date:      currentDate,
-					ShiftType: models.ShiftDay,
-					Status:    models.StatusOffDuty,
-				})
-			}
-		}
+	weeks, generated, skipped, err := generateRange(database.DB, startWeek, endWeek, opts)
+	if err != nil {
+		c.JSON(generationErrorResponse(err))
+		return
+	}
 
-		// Female Officer 2: Day shift Sun-Fri, off Saturday
-		if female2.ID > 0 {
-			if weekday != time.Saturday {
-				shifts = append(shifts, models.Shift{
-					OfficerID: female2.ID,
-					Date:      currentDate,
-					ShiftType: models.ShiftDay,
-					Status:    models.StatusOnDuty,
-				})
-			} else {
-				shifts = append(shifts, models.Shift{
-					OfficerID: female2.ID,
-					Date:      currentDate,
-					ShiftType: models.ShiftDay,
-					Status:    models.StatusOffDuty,
-				})
-			}
-		}
+	status := http.StatusCreated
+	message := "Rota generated successfully"
+	if opts.DryRun {
+		status = http.StatusOK
+		message = "Dry run: rota not saved"
+	}
 
-		// Handle Sunday special case
-		if weekday == time.Sunday {
-			// Sunday day shift: 2 officers from day team (who worked Sat day)
-			for i, officer := range dayTeamOfficers {
-				if i < 2 {
-					shifts = append(shifts, models.Shift{
-						OfficerID: officer.ID,
-						Date:      currentDate,
-						ShiftType: models.ShiftDay,
-						Status:    models.StatusOnDuty,
-					})
-				} else {
-					// Rest of day team is off on Sunday (they worked Sat night prev week)
-					shifts = append(shifts, models.Shift{
-						OfficerID: officer.ID,
-						Date:      currentDate,
-						ShiftType: models.ShiftDay,
-						Status:    models.StatusOffDuty,
-					})
-				}
-			}
+	c.JSON(status, gin.H{
+		"message":         message,
+		"start_week":      input.StartWeek,
+		"end_week":        input.EndWeek,
+		"weeks_generated": generated,
+		"weeks_skipped":   skipped,
+		"weeks":           weeks,
+	})
+}
 
-			// Sunday night shift: all night team officers
-			for _, officer := range nightTeamOfficers {
-				shifts = append(shifts, models.Shift{
-					OfficerID: officer.ID,
-					Date:      currentDate,
-					ShiftType: models.ShiftNight,
-					Status:    models.StatusOnDuty,
-				})
-			}
-		} else if weekday == time.Saturday {
-			// Saturday: All day team on duty, night team on duty
-			for _, officer := range dayTeamOfficers {
-				shifts = append(shifts, models.Shift{
-					OfficerID: officer.ID,
-					Date:      currentDate,
-					ShiftType: models.ShiftDay,
-					Status:    models.StatusOnDuty,
-				})
-			}
-			for _, officer := range nightTeamOfficers {
-				shifts = append(shifts, models.Shift{
-					OfficerID: officer.ID,
-					Date:      currentDate,
-					ShiftType: models.ShiftNight,
-					Status:    models.StatusOnDuty,
-				})
-			}
-		} else {
-			// Monday-Friday
-			// Day team all on duty
-			for _, officer := range dayTeamOfficers {
-				shifts = append(shifts, models.Shift{
-					OfficerID: officer.ID,
-					Date:      currentDate,
-					ShiftType: models.ShiftDay,
-					Status:    models.StatusOnDuty,
-				})
-			}
+// DeleteWeekRota godoc
+// @Summary Delete a week's rota
+// @Description Delete the week rotation and all shifts for a week so it can be generated again
+// @Tags shifts
+// @Produce json
+// @Param week_start query string true "Week start date (Sunday, YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /shifts/week [delete]
+func DeleteWeekRota(c *gin.Context) {
+	weekStartStr := c.Query("week_start")
+	if weekStartStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "week_start is required"})
+		return
+	}
 
-			// Night team: 2 officers off each day Mon-Thu (rotating)
-			dayIndex := int(weekday) - 1 // Mon=0, Tue=1, Wed=2, Thu=3
-			for i, officer := range nightTeamOfficers {
-				status := models.StatusOnDuty
-				// Simple rotation: 2 officers off based on day index
-				if dayIndex >= 0 && dayIndex < 4 { // Mon-Thu
-					offStart := (dayIndex * 2) % len(nightTeamOfficers)
-					if len(nightTeamOfficers) > 0 {
-						offEnd := (offStart + 2) % len(nightTeamOfficers)
-						if offStart < offEnd {
-							if i >= offStart && i < offEnd {
-								status = models.StatusOffDuty
-							}
-						} else {
-							if i >= offStart || i < offEnd {
-								status = models.StatusOffDuty
-							}
-						}
-					}
-				}
-				shifts = append(shifts, models.Shift{
-					OfficerID: officer.ID,
-					Date:      currentDate,
-					ShiftType: models.ShiftNight,
-					Status:    status,
-				})
-			}
-		}
+	weekStart, err := time.Parse("2006-01-02", weekStartStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+		return
+	}
+
+	if weekStart.Weekday() != time.Sunday {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "week_start must be a Sunday"})
+		return
+	}
+
+	var rotation models.WeekRotation
+	rotationErr := database.DB.Where("week_start = ?", weekStart).First(&rotation).Error
+
+	var shifts []models.Shift
+	database.DB.Where("date >= ? AND date <= ?", weekStart, weekStart.AddDate(0, 0, 6)).Find(&shifts)
+
+	if rotationErr != nil && len(shifts) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No rota found for this week"})
+		return
 	}
 
-	// Batch insert shifts
-	if len(shifts) > 0 {
-		database.DB.Create(&shifts)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if len(shifts) > 0 {
+			if err := deleteShifts(tx, shifts); err != nil {
+				return err
+			}
+		}
+		if rotationErr == nil {
+			return tx.Delete(&rotation).Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete rota"})
+		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message":          "Rota generated successfully",
-		"week_start":       input.WeekStart,
-		"day_shift_team":   dayShiftTeam,
-		"night_shift_team": nightShiftTeam,
-		"shifts_created":   len(shifts),
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Rota deleted",
+		"week_start":     weekStartStr,
+		"shifts_deleted": len(shifts),
 	})
 }
 
@@ -336,3 +324,310 @@ func GetWeekRotation(c *gin.Context) {
 
 	c.JSON(http.StatusOK, rotation)
 }
+
+// ShiftInput represents a single shift created or replaced by hand
+type ShiftInput struct {
+	OfficerID uint   `json:"officer_id" binding:"required"`
+	Date      string `json:"date" binding:"required"`       // YYYY-MM-DD
+	ShiftType string `json:"shift_type" binding:"required"` // Code of a shift definition
+	Status    string `json:"status" binding:"required,oneof=on_duty off_duty on_leave"`
+	Relief    bool   `json:"relief"` // Acting sergeant supervising the shift
+}
+
+// PatchShiftInput represents a partial change to a shift; omitted fields are left as they are
+type PatchShiftInput struct {
+	OfficerID *uint   `json:"officer_id"`
+	Date      *string `json:"date"` // YYYY-MM-DD
+	ShiftType *string `json:"shift_type"`
+	Status    *string `json:"status" binding:"omitempty,oneof=on_duty off_duty on_leave"`
+	Relief    *bool   `json:"relief"`
+}
+
+// GetShift godoc
+// @Summary Get a shift by ID
+// @Description Get a single shift by ID
+// @Tags shifts
+// @Produce json
+// @Param id path int true "Shift ID"
+// @Success 200 {object} models.Shift
+// @Failure 404 {object} map[string]string
+// @Router /shifts/{id} [get]
+func GetShift(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var shift models.Shift
+	if err := database.DB.Preload("Officer", withFormerOfficers).First(&shift, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shift not found"})
+		return
+	}
+	c.JSON(http.StatusOK, shift)
+}
+
+// CreateShift godoc
+// @Summary Create a shift
+// @Description Add a single shift by hand. It is flagged manual and kept when the week is regenerated.
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param input body ShiftInput true "Shift"
+// @Success 201 {object} models.Shift
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /shifts [post]
+func CreateShift(c *gin.Context) {
+	var input ShiftInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", input.Date)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+		return
+	}
+
+	shift := models.Shift{
+		OfficerID: input.OfficerID,
+		Date:      date,
+		ShiftType: models.ShiftType(input.ShiftType),
+		Status:    models.DutyStatus(input.Status),
+		Manual:    true,
+		Relief:    input.Relief,
+	}
+
+	if status, err := validateShift(database.DB, shift); err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Create(&shift).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create shift"})
+		return
+	}
+
+	database.DB.Preload("Officer", withFormerOfficers).First(&shift, shift.ID)
+	c.JSON(http.StatusCreated, shift)
+}
+
+// UpdateShift godoc
+// @Summary Replace a shift
+// @Description Replace a shift's officer, date, type and status. The shift is flagged manual and kept when the week is regenerated.
+// @Description Moving it to an officer who is off duty that day replaces their off-duty row; shifts on leave cannot be moved.
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param id path int true "Shift ID"
+// @Param input body ShiftInput true "Shift"
+// @Success 200 {object} models.Shift
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /shifts/{id} [put]
+func UpdateShift(c *gin.Context) {
+	var input ShiftInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updateShift(c, PatchShiftInput{
+		OfficerID: &input.OfficerID,
+		Date:      &input.Date,
+		ShiftType: &input.ShiftType,
+		Status:    &input.Status,
+		Relief:    &input.Relief,
+	})
+}
+
+// PatchShift godoc
+// @Summary Update part of a shift
+// @Description Change any of a shift's officer, date, type or status, e.g. mark an officer who called in sick as on_leave.
+// @Description The shift is flagged manual and kept when the week is regenerated. Moving it to an officer who is off duty
+// @Description that day replaces their off-duty row; an officer already on duty or on leave that day, or moving a shift
+// @Description that is on leave, is a conflict.
+// @Tags shifts
+// @Accept json
+// @Produce json
+// @Param id path int true "Shift ID"
+// @Param input body PatchShiftInput true "Fields to change"
+// @Success 200 {object} models.Shift
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /shifts/{id} [patch]
+func PatchShift(c *gin.Context) {
+	var input PatchShiftInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updateShift(c, input)
+}
+
+// updateShift applies changes to the shift in the path for PUT and PATCH
+func updateShift(c *gin.Context, input PatchShiftInput) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var shift models.Shift
+	if err := database.DB.First(&shift, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shift not found"})
+		return
+	}
+	original := shift
+
+	if input.OfficerID != nil {
+		shift.OfficerID = *input.OfficerID
+	}
+	if input.Date != nil {
+		date, err := time.Parse("2006-01-02", *input.Date)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format, use YYYY-MM-DD"})
+			return
+		}
+		shift.Date = date
+	}
+	if input.ShiftType != nil {
+		shift.ShiftType = models.ShiftType(*input.ShiftType)
+	}
+	if input.Status != nil {
+		shift.Status = models.DutyStatus(*input.Status)
+	}
+	if input.Relief != nil {
+		shift.Relief = *input.Relief
+	} else if shift.Status != models.StatusOnDuty || shift.OfficerID != original.OfficerID {
+		// The relief mark stays with the officer who was on duty
+		shift.Relief = false
+	}
+	shift.Manual = true
+	moved := shift.OfficerID != original.OfficerID || !shift.Date.Equal(original.Date)
+	if moved && original.Status == models.StatusOnLeave {
+		c.JSON(http.StatusConflict, gin.H{"error": "A shift on leave cannot be moved; change the leave instead"})
+		return
+	}
+
+	// Moving the shift onto an officer's day off replaces their off-duty row for that day
+	var dayOff *models.Shift
+	if moved {
+		var existing models.Shift
+		if database.DB.Where("officer_id = ? AND date = ? AND id <> ? AND status = ?",
+			shift.OfficerID, shift.Date, shift.ID, models.StatusOffDuty).First(&existing).Error == nil {
+			dayOff = &existing
+		}
+	}
+
+	var replaces []uint
+	if dayOff != nil {
+		replaces = append(replaces, dayOff.ID)
+	}
+	if status, err := validateShift(database.DB, shift, replaces...); err != nil {
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if dayOff != nil {
+			if err := deleteShifts(tx, []models.Shift{*dayOff}); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&shift).Updates(map[string]interface{}{
+			"officer_id": shift.OfficerID,
+			"date":       shift.Date,
+			"shift_type": shift.ShiftType,
+			"status":     shift.Status,
+			"manual":     true,
+			"relief":     shift.Relief,
+		}).Error; err != nil {
+			return err
+		}
+
+		// An on-duty shift moved away from an officer leaves them off duty that day, so regenerating
+		// the week does not put them back on
+		if moved && original.Status == models.StatusOnDuty {
+			return tx.Create(&models.Shift{
+				OfficerID: original.OfficerID,
+				Date:      original.Date,
+				ShiftType: original.ShiftType,
+				Status:    models.StatusOffDuty,
+				Manual:    true,
+			}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update shift"})
+		return
+	}
+
+	database.DB.Preload("Officer", withFormerOfficers).First(&shift, shift.ID)
+	c.JSON(http.StatusOK, shift)
+}
+
+// DeleteShift godoc
+// @Summary Delete a shift
+// @Description Delete a single shift along with any swap requests that refer to it
+// @Tags shifts
+// @Param id path int true "Shift ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /shifts/{id} [delete]
+func DeleteShift(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var shift models.Shift
+	if err := database.DB.First(&shift, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shift not found"})
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return deleteShifts(tx, []models.Shift{shift})
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete shift"})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// validateShift checks a hand-edited shift against the officer's other shifts and leave, ignoring the rows
+// in replaces that the edit will remove. It returns the HTTP status to report with the error.
+func validateShift(db *gorm.DB, shift models.Shift, replaces ...uint) (int, error) {
+	var officer models.Officer
+	if err := db.First(&officer, shift.OfficerID).Error; err != nil {
+		return http.StatusBadRequest, errors.New("Officer not found")
+	}
+
+	if err := validateShiftTypes(db, shift.ShiftType); err != nil {
+		return http.StatusBadRequest, err
+	}
+
+	// Officers have a single row per day
+	var existing models.Shift
+	ignore := append([]uint{shift.ID}, replaces...)
+	err := db.Where("officer_id = ? AND date = ? AND id NOT IN ?", shift.OfficerID, shift.Date, ignore).First(&existing).Error
+	if err == nil {
+		return http.StatusConflict, fmt.Errorf("%s already has a %s shift on %s (shift %d)",
+			officer.Name, existing.ShiftType, shift.Date.Format("2006-01-02"), existing.ID)
+	}
+
+	if shift.Relief && (shift.Status != models.StatusOnDuty || !officer.ActingSergeant) {
+		return http.StatusBadRequest, fmt.Errorf("Only an on-duty acting sergeant can be the relief; %s is not", officer.Name)
+	}
+
+	if shift.Status == models.StatusOnDuty && !officer.ActiveOn(shift.Date) {
+		return http.StatusConflict, fmt.Errorf("%s is not active on %s", officer.Name, shift.Date.Format("2006-01-02"))
+	}
+
+	if shift.Status == models.StatusOnDuty {
+		leaves, err := loadApprovedLeaves(db, shift.Date, shift.Date)
+		if err != nil {
+			return http.StatusInternalServerError, errors.New("Failed to check leave")
+		}
+		if findLeave(leaves, shift.OfficerID, shift.Date) != nil {
+			return http.StatusConflict, fmt.Errorf("%s is on approved leave on %s", officer.Name, shift.Date.Format("2006-01-02"))
+		}
+	}
+
+	return http.StatusOK, nil
+}
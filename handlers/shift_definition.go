@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ShiftDefinitionInput represents the times and display of a shift
+type ShiftDefinitionInput struct {
+	Label     string `json:"label" binding:"required"`
+	StartTime string `json:"start_time" binding:"required"`             // HH:MM
+	EndTime   string `json:"end_time" binding:"required"`               // HH:MM; at or before start_time ends the next day
+	Colour    string `json:"colour" binding:"omitempty,hexcolor,len=7"` // #RRGGBB
+	SortOrder int    `json:"sort_order"`
+}
+
+// CreateShiftDefinitionInput represents a new shift; its code cannot be changed later
+type CreateShiftDefinitionInput struct {
+	Code string `json:"code" binding:"required,max=20"` // e.g. morning; stored as the shift_type of shifts
+	ShiftDefinitionInput
+}
+
+var shiftCodePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// GetShiftDefinitions godoc
+// @Summary Get shift definitions
+// @Description Get the shifts the site works, with their times and colours, in display order
+// @Tags shift-definitions
+// @Produce json
+// @Success 200 {array} models.ShiftDefinition
+// @Router /shift-definitions [get]
+func GetShiftDefinitions(c *gin.Context) {
+	definitions, err := loadShiftDefinitions(database.DB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load shift definitions"})
+		return
+	}
+	c.JSON(http.StatusOK, definitions)
+}
+
+// GetShiftDefinition godoc
+// @Summary Get a shift definition by ID
+// @Description Get a single shift definition by ID
+// @Tags shift-definitions
+// @Produce json
+// @Param id path int true "Shift definition ID"
+// @Success 200 {object} models.ShiftDefinition
+// @Failure 404 {object} map[string]string
+// @Router /shift-definitions/{id} [get]
+func GetShiftDefinition(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var definition models.ShiftDefinition
+	if err := database.DB.First(&definition, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shift definition not found"})
+		return
+	}
+	c.JSON(http.StatusOK, definition)
+}
+
+// CreateShiftDefinition godoc
+// @Summary Create a shift definition
+// @Description Define a shift, e.g. morning 06:00-14:00. Rotation cycles assign teams to it by code.
+// @Tags shift-definitions
+// @Accept json
+// @Produce json
+// @Param input body CreateShiftDefinitionInput true "Shift definition"
+// @Success 201 {object} models.ShiftDefinition
+// @Failure 400 {object} map[string]string
+// @Router /shift-definitions [post]
+func CreateShiftDefinition(c *gin.Context) {
+	var input CreateShiftDefinitionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !shiftCodePattern.MatchString(input.Code) || input.Code == models.CycleRest {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "code must be lowercase letters, digits, - or _ and not 'rest'"})
+		return
+	}
+
+	definition := models.ShiftDefinition{Code: models.ShiftType(input.Code)}
+	if err := input.apply(&definition); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := database.DB.Create(&definition).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create shift definition (duplicate code?)"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, definition)
+}
+
+// UpdateShiftDefinition godoc
+// @Summary Update a shift definition
+// @Description Change a shift's label, times, colour or display order. The code cannot be changed.
+// @Tags shift-definitions
+// @Accept json
+// @Produce json
+// @Param id path int true "Shift definition ID"
+// @Param input body ShiftDefinitionInput true "Shift definition"
+// @Success 200 {object} models.ShiftDefinition
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /shift-definitions/{id} [put]
+func UpdateShiftDefinition(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var definition models.ShiftDefinition
+	if err := database.DB.First(&definition, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shift definition not found"})
+		return
+	}
+
+	var input ShiftDefinitionInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := input.apply(&definition); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := database.DB.Model(&definition).Updates(map[string]interface{}{
+		"label":            definition.Label,
+		"start_time":       definition.StartTime,
+		"end_time":         definition.EndTime,
+		"crosses_midnight": definition.CrossesMidnight,
+		"colour":           definition.Colour,
+		"sort_order":       definition.SortOrder,
+	}).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update shift definition"})
+		return
+	}
+
+	c.JSON(http.StatusOK, definition)
+}
+
+// DeleteShiftDefinition godoc
+// @Summary Delete a shift definition
+// @Description Delete a shift that no shift, rule or rotation cycle refers to
+// @Tags shift-definitions
+// @Param id path int true "Shift definition ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /shift-definitions/{id} [delete]
+func DeleteShiftDefinition(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var definition models.ShiftDefinition
+	if err := database.DB.First(&definition, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shift definition not found"})
+		return
+	}
+
+	for _, use := range []struct {
+		model  interface{}
+		column string
+		name   string
+	}{
+		{&models.Shift{}, "shift_type", "shifts"},
+		{&models.ShiftTemplate{}, "shift_type", "rule set templates"},
+		{&models.StaffingRule{}, "shift_type", "staffing rules"},
+		{&models.CoverageRule{}, "shift_type", "coverage rules"},
+		{&models.RotationCycleStep{}, "assignment", "rotation cycles"},
+	} {
+		var count int64
+		database.DB.Model(use.model).Where(use.column+" = ?", definition.Code).Count(&count)
+		if count > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Shift %s is used by %d %s", definition.Code, count, use.name)})
+			return
+		}
+	}
+
+	database.DB.Delete(&definition)
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// apply copies the input onto a definition; an end time at or before the start time crosses midnight
+func (input ShiftDefinitionInput) apply(d *models.ShiftDefinition) error {
+	start, err := time.Parse("15:04", input.StartTime)
+	if err != nil {
+		return errors.New("Invalid start_time, use HH:MM")
+	}
+	end, err := time.Parse("15:04", input.EndTime)
+	if err != nil {
+		return errors.New("Invalid end_time, use HH:MM")
+	}
+
+	d.Label = input.Label
+	d.StartTime = start.Format("15:04")
+	d.EndTime = end.Format("15:04")
+	d.CrossesMidnight = !end.After(start)
+	d.Colour = strings.ToUpper(input.Colour)
+	if d.Colour == "" {
+		d.Colour = "#FFFFFF"
+	}
+	d.SortOrder = input.SortOrder
+	return nil
+}
+
+// loadShiftDefinitions returns the defined shifts in display order, falling back to the built-in day and night
+func loadShiftDefinitions(db *gorm.DB) ([]models.ShiftDefinition, error) {
+	var definitions []models.ShiftDefinition
+	if err := db.Order("sort_order ASC, id ASC").Find(&definitions).Error; err != nil {
+		return nil, err
+	}
+	if len(definitions) == 0 {
+		return models.DefaultShiftDefinitions(), nil
+	}
+	return definitions, nil
+}
+
+// validateShiftTypes checks that every shift type is the code of a shift definition
+func validateShiftTypes(db *gorm.DB, shiftTypes ...models.ShiftType) error {
+	definitions, err := loadShiftDefinitions(db)
+	if err != nil {
+		return err
+	}
+	for _, shiftType := range shiftTypes {
+		if findShiftDefinition(definitions, shiftType) == nil {
+			return fmt.Errorf("Unknown shift type: %s", shiftType)
+		}
+	}
+	return nil
+}
+
+// findShiftDefinition returns the definition with a code, if any
+func findShiftDefinition(definitions []models.ShiftDefinition, code models.ShiftType) *models.ShiftDefinition {
+	for i := range definitions {
+		if definitions[i].Code == code {
+			return &definitions[i]
+		}
+	}
+	return nil
+}
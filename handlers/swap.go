@@ -0,0 +1,437 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateSwapInput represents an officer's request to trade shifts
+type CreateSwapInput struct {
+	RequesterShiftID uint   `json:"requester_shift_id" binding:"required"` // Shift the requester gives away
+	TargetShiftID    uint   `json:"target_shift_id" binding:"required"`    // Colleague's shift the requester takes
+	Reason           string `json:"reason"`
+}
+
+// GetSwapsInput represents query params for listing swaps
+type GetSwapsInput struct {
+	Status    string `form:"status"`
+	OfficerID uint   `form:"officer_id"` // Requester or target
+}
+
+// GetSwaps godoc
+// @Summary Get shift swap requests
+// @Description Get shift swap requests with optional filters (status, officer_id)
+// @Tags swaps
+// @Produce json
+// @Param status query string false "pending, accepted, approved, declined, rejected or cancelled"
+// @Param officer_id query int false "Requester or target officer ID"
+// @Success 200 {array} models.ShiftSwap
+// @Router /swaps [get]
+func GetSwaps(c *gin.Context) {
+	var input GetSwapsInput
+	c.ShouldBindQuery(&input)
+
+	query := database.DB.Preload("Requester", withFormerOfficers).Preload("TargetOfficer", withFormerOfficers).
+		Preload("RequesterShift").Preload("TargetShift")
+
+	if input.Status != "" {
+		query = query.Where("status = ?", input.Status)
+	}
+
+	if input.OfficerID > 0 {
+		query = query.Where("requester_id = ? OR target_officer_id = ?", input.OfficerID, input.OfficerID)
+	}
+
+	var swaps []models.ShiftSwap
+	query.Order("created_at DESC").Find(&swaps)
+	c.JSON(http.StatusOK, swaps)
+}
+
+// GetSwap godoc
+// @Summary Get a shift swap request by ID
+// @Description Get a single shift swap request by ID
+// @Tags swaps
+// @Produce json
+// @Param id path int true "Swap ID"
+// @Success 200 {object} models.ShiftSwap
+// @Failure 404 {object} map[string]string
+// @Router /swaps/{id} [get]
+func GetSwap(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var swap models.ShiftSwap
+	if err := database.DB.Preload("Requester", withFormerOfficers).Preload("TargetOfficer", withFormerOfficers).
+		Preload("RequesterShift").Preload("TargetShift").
+		First(&swap, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Swap not found"})
+		return
+	}
+	c.JSON(http.StatusOK, swap)
+}
+
+// CreateSwap godoc
+// @Summary Propose a shift swap
+// @Description Propose trading one of the requester's on-duty shifts for a colleague's on-duty shift
+// @Tags swaps
+// @Accept json
+// @Produce json
+// @Param input body CreateSwapInput true "Shifts to trade"
+// @Success 201 {object} models.ShiftSwap
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /swaps [post]
+func CreateSwap(c *gin.Context) {
+	var input CreateSwapInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var requesterShift, targetShift models.Shift
+	if err := database.DB.First(&requesterShift, input.RequesterShiftID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Requester shift not found"})
+		return
+	}
+	if err := database.DB.First(&targetShift, input.TargetShiftID).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Target shift not found"})
+		return
+	}
+
+	if !actsForOfficer(c, requesterShift.OfficerID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only offer your own shifts"})
+		return
+	}
+
+	if requesterShift.OfficerID == targetShift.OfficerID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Both shifts belong to the same officer"})
+		return
+	}
+
+	if requesterShift.Status != models.StatusOnDuty || targetShift.Status != models.StatusOnDuty {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only on-duty shifts can be swapped"})
+		return
+	}
+
+	if requesterShift.Date.Equal(targetShift.Date) && requesterShift.ShiftType == targetShift.ShiftType {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Both shifts are the same duty; nothing to swap"})
+		return
+	}
+
+	today := time.Now().Truncate(24 * time.Hour)
+	if requesterShift.Date.Before(today) || targetShift.Date.Before(today) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Past shifts cannot be swapped"})
+		return
+	}
+
+	swap := models.ShiftSwap{
+		RequesterID:      requesterShift.OfficerID,
+		RequesterShiftID: requesterShift.ID,
+		TargetOfficerID:  targetShift.OfficerID,
+		TargetShiftID:    targetShift.ID,
+		Status:           models.SwapPending,
+		Reason:           input.Reason,
+	}
+
+	if err := database.DB.Create(&swap).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, swap)
+}
+
+// AcceptSwap godoc
+// @Summary Accept a shift swap
+// @Description The colleague accepts a pending swap, sending it to a supervisor for approval
+// @Tags swaps
+// @Produce json
+// @Param id path int true "Swap ID"
+// @Success 200 {object} models.ShiftSwap
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /swaps/{id}/accept [post]
+func AcceptSwap(c *gin.Context) {
+	respondToSwap(c, models.SwapAccepted)
+}
+
+// DeclineSwap godoc
+// @Summary Decline a shift swap
+// @Description The colleague declines a pending swap
+// @Tags swaps
+// @Produce json
+// @Param id path int true "Swap ID"
+// @Success 200 {object} models.ShiftSwap
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /swaps/{id}/decline [post]
+func DeclineSwap(c *gin.Context) {
+	respondToSwap(c, models.SwapDeclined)
+}
+
+// respondToSwap records the colleague's answer to a pending swap
+func respondToSwap(c *gin.Context, status models.SwapStatus) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var swap models.ShiftSwap
+	if err := database.DB.First(&swap, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Swap not found"})
+		return
+	}
+
+	if !actsForOfficer(c, swap.TargetOfficerID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the colleague named in the swap can respond"})
+		return
+	}
+
+	if swap.Status != models.SwapPending {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Swap is not pending"})
+		return
+	}
+
+	database.DB.Model(&swap).Update("status", status)
+	c.JSON(http.StatusOK, swap)
+}
+
+// CancelSwap godoc
+// @Summary Cancel a shift swap
+// @Description The requester withdraws a swap that has not been approved yet
+// @Tags swaps
+// @Produce json
+// @Param id path int true "Swap ID"
+// @Success 200 {object} models.ShiftSwap
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Router /swaps/{id}/cancel [post]
+func CancelSwap(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var swap models.ShiftSwap
+	if err := database.DB.First(&swap, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Swap not found"})
+		return
+	}
+
+	if !actsForOfficer(c, swap.RequesterID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the requester can cancel a swap"})
+		return
+	}
+
+	if swap.Status != models.SwapPending && swap.Status != models.SwapAccepted {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Swap can no longer be cancelled"})
+		return
+	}
+
+	database.DB.Model(&swap).Update("status", models.SwapCancelled)
+	c.JSON(http.StatusOK, swap)
+}
+
+// RejectSwap godoc
+// @Summary Reject a shift swap
+// @Description A supervisor rejects an accepted swap
+// @Tags swaps
+// @Produce json
+// @Param id path int true "Swap ID"
+// @Success 200 {object} models.ShiftSwap
+// @Failure 400 {object} map[string]string
+// @Router /swaps/{id}/reject [post]
+func RejectSwap(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var swap models.ShiftSwap
+	if err := database.DB.First(&swap, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Swap not found"})
+		return
+	}
+
+	if swap.Status != models.SwapPending && swap.Status != models.SwapAccepted {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Swap is already closed"})
+		return
+	}
+
+	database.DB.Model(&swap).Updates(swapReview(c, models.SwapRejected))
+	c.JSON(http.StatusOK, swap)
+}
+
+// ApproveSwap godoc
+// @Summary Approve a shift swap
+// @Description A supervisor approves an accepted swap. Both shifts change hands in one transaction.
+// @Description The swap is refused if it would leave a shift without required coverage.
+// @Tags swaps
+// @Produce json
+// @Param id path int true "Swap ID"
+// @Success 200 {object} models.ShiftSwap
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]interface{}
+// @Router /swaps/{id}/approve [post]
+func ApproveSwap(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	tx := database.DB.Begin()
+
+	// The swap and both shifts are locked until the approval commits, so concurrent approvals and
+	// shift edits wait and then see the result
+	var swap models.ShiftSwap
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&swap, id).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Swap not found"})
+		return
+	}
+
+	if swap.Status != models.SwapAccepted {
+		tx.Rollback()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only swaps accepted by the colleague can be approved"})
+		return
+	}
+
+	// Re-read both shifts, in ID order to avoid deadlocks; they may have changed since the swap was proposed
+	var locked []models.Shift
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", []uint{swap.RequesterShiftID, swap.TargetShiftID}).
+		Order("id ASC").
+		Find(&locked).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load the swap's shifts"})
+		return
+	}
+	if len(locked) != 2 {
+		tx.Rollback()
+		c.JSON(http.StatusConflict, gin.H{"error": "A shift in the swap has been deleted since the swap was requested"})
+		return
+	}
+	var requesterShift, targetShift models.Shift
+	for _, shift := range locked {
+		switch shift.ID {
+		case swap.RequesterShiftID:
+			requesterShift = shift
+		case swap.TargetShiftID:
+			targetShift = shift
+		}
+	}
+	if requesterShift.OfficerID != swap.RequesterID || targetShift.OfficerID != swap.TargetOfficerID ||
+		requesterShift.Status != models.StatusOnDuty || targetShift.Status != models.StatusOnDuty {
+		tx.Rollback()
+		c.JSON(http.StatusConflict, gin.H{"error": "The shifts have changed since the swap was requested"})
+		return
+	}
+
+	dates := []time.Time{requesterShift.Date}
+	if !targetShift.Date.Equal(requesterShift.Date) {
+		dates = append(dates, targetShift.Date)
+	}
+
+	var before []string
+	for _, d := range dates {
+		before = append(before, coverageShortfalls(tx, d)...)
+	}
+
+	if err := applySwap(tx, requesterShift, targetShift); err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	var after []string
+	for _, d := range dates {
+		after = append(after, coverageShortfalls(tx, d)...)
+	}
+
+	if added := newShortfalls(before, after); len(added) > 0 {
+		tx.Rollback()
+		c.JSON(http.StatusConflict, gin.H{
+			"error":      "Swap would break shift coverage",
+			"shortfalls": added,
+		})
+		return
+	}
+
+	if err := tx.Model(&swap).Updates(swapReview(c, models.SwapApproved)).Error; err != nil {
+		tx.Rollback()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve swap"})
+		return
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve swap"})
+		return
+	}
+
+	c.JSON(http.StatusOK, swap)
+}
+
+// swapError reports why a swap cannot be applied
+type swapError string
+
+func (e swapError) Error() string { return string(e) }
+
+// applySwap hands the requester's shift to the target officer and the target's shift to the requester.
+// For swaps across two dates, each officer's own row on the other date is exchanged as well so
+// both officers keep a single row per day.
+func applySwap(tx *gorm.DB, requesterShift, targetShift models.Shift) error {
+	if requesterShift.Date.Equal(targetShift.Date) {
+		return exchangeShifts(tx, requesterShift, targetShift)
+	}
+
+	if err := handOver(tx, requesterShift, targetShift.OfficerID); err != nil {
+		return err
+	}
+	return handOver(tx, targetShift, requesterShift.OfficerID)
+}
+
+// handOver gives a shift to another officer, trading it for that officer's row on the same date
+func handOver(tx *gorm.DB, shift models.Shift, toOfficerID uint) error {
+	var counterpart models.Shift
+	if tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("officer_id = ? AND date = ?", toOfficerID, shift.Date).First(&counterpart).Error != nil {
+		return tx.Model(&shift).Updates(map[string]interface{}{"officer_id": toOfficerID, "manual": true, "relief": false}).Error
+	}
+
+	if counterpart.Status != models.StatusOffDuty {
+		return swapError("Officer is not free on " + shift.Date.Format("2006-01-02"))
+	}
+
+	return exchangeShifts(tx, shift, counterpart)
+}
+
+// exchangeShifts swaps the officers of two shifts on the same date.
+// Both shifts are flagged manual so regenerating the week keeps the swap; relief marks are cleared.
+func exchangeShifts(tx *gorm.DB, a, b models.Shift) error {
+	// Same duty slot: exchanging officers is equivalent to exchanging statuses
+	if a.ShiftType == b.ShiftType {
+		if err := tx.Model(&a).Updates(map[string]interface{}{"status": b.Status, "manual": true, "relief": false}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&b).Updates(map[string]interface{}{"status": a.Status, "manual": true, "relief": false}).Error
+	}
+
+	if err := tx.Model(&a).Updates(map[string]interface{}{"officer_id": b.OfficerID, "manual": true, "relief": false}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&b).Updates(map[string]interface{}{"officer_id": a.OfficerID, "manual": true, "relief": false}).Error
+}
+
+// swapReview returns the column updates for a supervisor decision
+func swapReview(c *gin.Context, status models.SwapStatus) map[string]interface{} {
+	updates := map[string]interface{}{
+		"status":      status,
+		"reviewed_at": time.Now(),
+	}
+	if userID, exists := c.Get("userID"); exists {
+		updates["reviewed_by"] = userID.(uint)
+	}
+	return updates
+}
+
+// actsForOfficer reports whether the current user may act on behalf of the officer.
+// Supervisors and admins may act for anyone; officer accounts only for their linked officer.
+func actsForOfficer(c *gin.Context, officerID uint) bool {
+	role := c.GetString("role")
+	if role == models.UserRoleAdmin || role == models.UserRoleSupervisor {
+		return true
+	}
+
+	user, ok := currentUser(c)
+	return ok && user.OfficerID != nil && *user.OfficerID == officerID
+}
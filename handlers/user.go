@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"securityrota-api/database"
+	"securityrota-api/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// CreateUserInput represents the input for creating a user
+type CreateUserInput struct {
+	Username  string `json:"username" binding:"required"`
+	Password  string `json:"password" binding:"required,min=8"`
+	FullName  string `json:"fullName"`
+	Email     string `json:"email"`
+	Role      string `json:"role" binding:"required,oneof=Admin Supervisor Viewer Officer"`
+	OfficerID *uint  `json:"officer_id"`
+}
+
+// UpdateUserInput represents the input for updating a user
+type UpdateUserInput struct {
+	FullName  string `json:"fullName"`
+	Email     string `json:"email"`
+	Role      string `json:"role" binding:"omitempty,oneof=Admin Supervisor Viewer Officer"`
+	Password  string `json:"password" binding:"omitempty,min=8"`
+	OfficerID *uint  `json:"officer_id"`
+}
+
+// GetUsers godoc
+// @Summary Get all users
+// @Description Get list of all user accounts
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.User
+// @Router /users [get]
+func GetUsers(c *gin.Context) {
+	var users []models.User
+	database.DB.Order("username ASC").Find(&users)
+	c.JSON(http.StatusOK, users)
+}
+
+// GetUser godoc
+// @Summary Get a user by ID
+// @Description Get a single user account by ID
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} models.User
+// @Failure 404 {object} map[string]string
+// @Router /users/{id} [get]
+func GetUser(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var user models.User
+	if err := database.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// CreateUser godoc
+// @Summary Create a new user
+// @Description Create a new user account with a hashed password
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body CreateUserInput true "User data"
+// @Success 201 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Router /users [post]
+func CreateUser(c *gin.Context) {
+	var input CreateUserInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+		return
+	}
+
+	user := models.User{
+		Username:     input.Username,
+		PasswordHash: string(hash),
+		FullName:     input.FullName,
+		Email:        input.Email,
+		Role:         input.Role,
+		OfficerID:    input.OfficerID,
+	}
+
+	if err := database.DB.Create(&user).Error; err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to create user (duplicate username?)"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, user)
+}
+
+// UpdateUser godoc
+// @Summary Update a user
+// @Description Update an existing user account; only the fields sent are changed, so fullName, email and officer_id can be cleared with "" or null, and a non-empty password is re-hashed
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param input body UpdateUserInput true "User data"
+// @Success 200 {object} models.User
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id} [put]
+func UpdateUser(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var user models.User
+	if err := database.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	// Bind twice so fields sent as "" or null can be told apart from fields left out
+	var input UpdateUserInput
+	var present map[string]json.RawMessage
+	if err := c.ShouldBindBodyWith(&input, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := c.ShouldBindBodyWith(&present, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if _, ok := present["fullName"]; ok {
+		updates["full_name"] = input.FullName
+	}
+	if _, ok := present["email"]; ok {
+		updates["email"] = input.Email
+	}
+	if input.Role != "" {
+		updates["role"] = input.Role
+	}
+	if _, ok := present["officer_id"]; ok {
+		updates["officer_id"] = input.OfficerID
+	}
+
+	if input.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to hash password"})
+			return
+		}
+		updates["password_hash"] = string(hash)
+	}
+
+	if len(updates) > 0 {
+		if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update user"})
+			return
+		}
+	}
+
+	database.DB.First(&user, user.ID)
+	c.JSON(http.StatusOK, user)
+}
+
+// DeleteUser godoc
+// @Summary Delete a user
+// @Description Delete a user account
+// @Tags users
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 204
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id} [delete]
+func DeleteUser(c *gin.Context) {
+	id, _ := strconv.Atoi(c.Param("id"))
+	var user models.User
+	if err := database.DB.First(&user, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if currentID, exists := c.Get("userID"); exists && currentID.(uint) == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot delete your own account"})
+		return
+	}
+
+	database.DB.Delete(&user)
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -1,15 +1,21 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"securityrota-api/database"
 	_ "securityrota-api/docs"
 	"securityrota-api/handlers"
+	"securityrota-api/models"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -30,7 +36,7 @@ func main() {
 	// CORS middleware
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173", "http://localhost:3000", "http://127.0.0.1:5173"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 		AllowCredentials: true,
 	}))
@@ -44,7 +50,11 @@ func main() {
 		// Auth routes (public)
 		v1.POST("/auth/login", handlers.Login)
 
-		// Protected routes
+		// Calendar feeds (public, authorized by the calendar token in the URL)
+		v1.GET("/calendar/officer/:file", handlers.GetOfficerCalendar)
+		v1.GET("/calendar/team/:file", handlers.GetTeamCalendar)
+
+		// Protected routes (any authenticated role may read)
 		protected := v1.Group("")
 		protected.Use(handlers.AuthMiddleware())
 		{
@@ -55,29 +65,148 @@ func main() {
 			// Officers
 			protected.GET("/officers", handlers.GetOfficers)
 			protected.GET("/officers/:id", handlers.GetOfficer)
-			protected.POST("/officers", handlers.CreateOfficer)
-			protected.PUT("/officers/:id", handlers.UpdateOfficer)
-			protected.DELETE("/officers/:id", handlers.DeleteOfficer)
+			protected.GET("/officers/:id/qualifications", handlers.GetQualifications)
+			protected.GET("/qualifications/expiring", handlers.GetExpiringQualifications)
 
 			// Shifts
 			protected.GET("/shifts", handlers.GetShifts)
-			protected.POST("/shifts/generate", handlers.GenerateWeekRota)
 			protected.GET("/shifts/rotation", handlers.GetWeekRotation)
+			protected.GET("/shifts/export.xlsx", handlers.ExportShiftsXLSX)
+			protected.GET("/shifts/:id", handlers.GetShift)
+
+			// Rotation rules
+			protected.GET("/rules", handlers.GetRuleSets)
+			protected.GET("/rules/:id", handlers.GetRuleSet)
+
+			// Rotation cycles
+			protected.GET("/cycles", handlers.GetCycles)
+			protected.GET("/cycles/:id", handlers.GetCycle)
+
+			// Shift definitions
+			protected.GET("/shift-definitions", handlers.GetShiftDefinitions)
+			protected.GET("/shift-definitions/:id", handlers.GetShiftDefinition)
 
 			// Rota View
 			protected.GET("/rota/week", handlers.GetWeekRota)
 			protected.GET("/rota/week/pdf", handlers.GetWeekRotaPDF)
 			protected.GET("/rota/week/docx", handlers.GetWeekRotaDOCX)
+			protected.GET("/rota/week/xlsx", handlers.GetWeekRotaXLSX)
+			protected.GET("/rota/month/pdf", handlers.GetMonthRotaPDF)
+			protected.GET("/rota/month/docx", handlers.GetMonthRotaDOCX)
+			protected.GET("/rota/week/coverage", handlers.GetWeekCoverage)
+			protected.GET("/rota/off-days", handlers.GetOffDayDistribution)
+
+			// Public holidays
+			protected.GET("/holidays", handlers.GetHolidays)
+			protected.GET("/holidays/:id", handlers.GetHoliday)
+
+			// Leave
+			protected.GET("/leave", handlers.GetLeaves)
+			protected.GET("/leave/:id", handlers.GetLeave)
+
+			// Shift swaps
+			protected.GET("/swaps", handlers.GetSwaps)
+			protected.GET("/swaps/:id", handlers.GetSwap)
+		}
+
+		// Officer routes - propose and answer shift swaps, manage own calendar feed
+		officer := protected.Group("")
+		officer.Use(handlers.RequireRole(models.UserRoleAdmin, models.UserRoleSupervisor, models.UserRoleOfficer))
+		{
+			officer.POST("/swaps", handlers.CreateSwap)
+			officer.POST("/swaps/:id/accept", handlers.AcceptSwap)
+			officer.POST("/swaps/:id/decline", handlers.DeclineSwap)
+			officer.POST("/swaps/:id/cancel", handlers.CancelSwap)
+
+			// Calendar feed tokens
+			officer.GET("/officers/:id/calendar-token", handlers.GetCalendarToken)
+			officer.POST("/officers/:id/calendar-token", handlers.CreateCalendarToken)
+			officer.DELETE("/officers/:id/calendar-token", handlers.DeleteCalendarToken)
+		}
+
+		// Supervisor routes - manage officers and rotas
+		supervisor := protected.Group("")
+		supervisor.Use(handlers.RequireRole(models.UserRoleAdmin, models.UserRoleSupervisor))
+		{
+			// Officers
+			supervisor.POST("/officers", handlers.CreateOfficer)
+			supervisor.PUT("/officers/:id", handlers.UpdateOfficer)
+			supervisor.DELETE("/officers/:id", handlers.DeleteOfficer)
+			supervisor.POST("/officers/:id/qualifications", handlers.CreateQualification)
+			supervisor.PUT("/officers/:id/qualifications/:qid", handlers.UpdateQualification)
+			supervisor.DELETE("/officers/:id/qualifications/:qid", handlers.DeleteQualification)
+
+			// Shifts
+			supervisor.POST("/shifts/generate", handlers.GenerateWeekRota)
+			supervisor.POST("/shifts/generate-range", handlers.GenerateRangeRota)
+			supervisor.DELETE("/shifts/week", handlers.DeleteWeekRota)
+			supervisor.POST("/shifts", handlers.CreateShift)
+			supervisor.PUT("/shifts/:id", handlers.UpdateShift)
+			supervisor.PATCH("/shifts/:id", handlers.PatchShift)
+			supervisor.DELETE("/shifts/:id", handlers.DeleteShift)
+
+			// Hours worked
+			supervisor.GET("/rota/hours", handlers.GetHoursReport)
+
+			// Public holidays
+			supervisor.POST("/holidays", handlers.CreateHoliday)
+			supervisor.POST("/holidays/import", handlers.ImportHolidays)
+			supervisor.PUT("/holidays/:id", handlers.UpdateHoliday)
+			supervisor.DELETE("/holidays/:id", handlers.DeleteHoliday)
+
+			// Leave
+			supervisor.POST("/leave", handlers.CreateLeave)
+			supervisor.PUT("/leave/:id", handlers.UpdateLeave)
+			supervisor.DELETE("/leave/:id", handlers.DeleteLeave)
+			supervisor.POST("/leave/:id/approve", handlers.ApproveLeave)
+			supervisor.POST("/leave/:id/reject", handlers.RejectLeave)
+
+			// Shift swaps
+			supervisor.POST("/swaps/:id/approve", handlers.ApproveSwap)
+			supervisor.POST("/swaps/:id/reject", handlers.RejectSwap)
+		}
+
+		// Admin routes - user management and data import
+		admin := protected.Group("")
+		admin.Use(handlers.RequireRole(models.UserRoleAdmin))
+		{
+			// Users
+			admin.GET("/users", handlers.GetUsers)
+			admin.GET("/users/:id", handlers.GetUser)
+			admin.POST("/users", handlers.CreateUser)
+			admin.PUT("/users/:id", handlers.UpdateUser)
+			admin.DELETE("/users/:id", handlers.DeleteUser)
+
+			// Rotation rules
+			admin.POST("/rules", handlers.CreateRuleSet)
+			admin.PUT("/rules/:id", handlers.UpdateRuleSet)
+			admin.DELETE("/rules/:id", handlers.DeleteRuleSet)
+			admin.POST("/rules/:id/activate", handlers.ActivateRuleSet)
+
+			// Rotation cycles
+			admin.POST("/cycles", handlers.CreateCycle)
+			admin.PUT("/cycles/:id", handlers.UpdateCycle)
+			admin.DELETE("/cycles/:id", handlers.DeleteCycle)
+			admin.POST("/cycles/:id/activate", handlers.ActivateCycle)
+
+			// Shift definitions
+			admin.POST("/shift-definitions", handlers.CreateShiftDefinition)
+			admin.PUT("/shift-definitions/:id", handlers.UpdateShiftDefinition)
+			admin.DELETE("/shift-definitions/:id", handlers.DeleteShiftDefinition)
 
 			// Admin - Import existing schedule
-			protected.POST("/admin/import-state", handlers.ImportCurrentState)
-			protected.POST("/admin/import-shifts", handlers.BulkImportShifts)
+			admin.POST("/admin/import-state", handlers.ImportCurrentState)
+			admin.POST("/admin/import-shifts", handlers.BulkImportShifts)
+			admin.POST("/admin/import-rota", handlers.ImportRotaGrid)
 
 			// CSV Import/Export
-			protected.GET("/admin/template/shifts", handlers.DownloadShiftsTemplate)
-			protected.GET("/admin/template/officers", handlers.DownloadOfficersTemplate)
-			protected.POST("/admin/import-shifts/csv", handlers.ImportShiftsCSV)
-			protected.POST("/admin/import-officers/csv", handlers.ImportOfficersCSV)
+			admin.GET("/admin/template/shifts", handlers.DownloadShiftsTemplate)
+			admin.GET("/admin/template/officers", handlers.DownloadOfficersTemplate)
+			admin.POST("/admin/import-shifts/csv", handlers.ImportShiftsCSV)
+			admin.POST("/admin/import-officers/csv", handlers.ImportOfficersCSV)
+
+			// Background generation
+			admin.GET("/admin/generation-runs", handlers.GetGenerationRuns)
 		}
 	}
 
@@ -104,7 +233,31 @@ func main() {
 		log.Println("Serving frontend from ./static")
 	}
 
-	log.Println("Server starting on :8080")
-	log.Println("Swagger UI: http://localhost:8080/swagger/index.html")
-	r.Run(":8080")
+	scheduler, err := handlers.StartScheduler()
+	if err != nil {
+		log.Fatal("Failed to start auto-generation:", err)
+	}
+
+	srv := &http.Server{Addr: ":8080", Handler: r}
+	go func() {
+		log.Println("Server starting on :8080")
+		log.Println("Swagger UI: http://localhost:8080/swagger/index.html")
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server failed:", err)
+		}
+	}()
+
+	// Shut down cleanly on SIGINT/SIGTERM, letting requests and a generation run in progress finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-ctx.Done()
+
+	log.Println("Shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	scheduler.Stop(shutdownCtx)
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Println("Server shutdown:", err)
+	}
 }
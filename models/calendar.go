@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// CalendarToken grants read access to an officer's calendar feed and their team's. Calendar apps
+// cannot send a JWT, so the token is passed in the feed URL; deleting it revokes the feed.
+type CalendarToken struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	OfficerID uint      `json:"officer_id" gorm:"uniqueIndex;not null"`
+	Token     string    `json:"token" gorm:"uniqueIndex;not null"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,79 @@
+package models
+
+import "time"
+
+// CycleRest marks a team that is off for the whole week of a cycle step
+const CycleRest = "rest"
+
+// RotationCycle defines how a number of teams move through shifts over a repeating sequence of weeks.
+// Only one cycle is active at a time.
+type RotationCycle struct {
+	ID        uint                `json:"id" gorm:"primaryKey"`
+	Name      string              `json:"name" gorm:"uniqueIndex;not null"`
+	TeamCount int                 `json:"team_count" gorm:"not null"`
+	Active    bool                `json:"active" gorm:"not null;default:false"`
+	Steps     []RotationCycleStep `json:"steps" gorm:"foreignKey:CycleID;constraint:OnDelete:CASCADE"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// RotationCycleStep assigns one team to a shift, or to rest, in one week of the cycle
+type RotationCycleStep struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	CycleID    uint   `json:"cycle_id" gorm:"not null;index"`
+	Week       int    `json:"week"` // 0-based position in the cycle
+	Team       int    `json:"team"`
+	Assignment string `json:"assignment" gorm:"not null"` // shift definition code or rest
+}
+
+// Length returns the number of weeks in the cycle
+func (rc RotationCycle) Length() int {
+	length := 0
+	for _, s := range rc.Steps {
+		if s.Week+1 > length {
+			length = s.Week + 1
+		}
+	}
+	return length
+}
+
+// Assignments returns each team's assignment for a week of the cycle.
+// Teams without a step rest that week.
+func (rc RotationCycle) Assignments(week int) map[int]string {
+	assignments := make(map[int]string, rc.TeamCount)
+	for team := 1; team <= rc.TeamCount; team++ {
+		assignments[team] = CycleRest
+	}
+	for _, s := range rc.Steps {
+		if s.Week == week {
+			assignments[s.Team] = s.Assignment
+		}
+	}
+	return assignments
+}
+
+// TeamOn returns the first team assigned to a shift in a week of the cycle, or 0 if none
+func (rc RotationCycle) TeamOn(week int, assignment string) int {
+	assignments := rc.Assignments(week)
+	for team := 1; team <= rc.TeamCount; team++ {
+		if assignments[team] == assignment {
+			return team
+		}
+	}
+	return 0
+}
+
+// DefaultRotationCycle returns the two-team weekly day/night alternation
+func DefaultRotationCycle() RotationCycle {
+	return RotationCycle{
+		Name:      "Two-team weekly alternation",
+		TeamCount: 2,
+		Active:    true,
+		Steps: []RotationCycleStep{
+			{Week: 0, Team: 1, Assignment: string(ShiftDay)},
+			{Week: 0, Team: 2, Assignment: string(ShiftNight)},
+			{Week: 1, Team: 2, Assignment: string(ShiftDay)},
+			{Week: 1, Team: 1, Assignment: string(ShiftNight)},
+		},
+	}
+}
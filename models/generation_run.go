@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// GenerationRunStatus defines the outcome of a scheduled generation run
+type GenerationRunStatus string
+
+const (
+	RunRunning   GenerationRunStatus = "running"
+	RunSucceeded GenerationRunStatus = "succeeded"
+	RunFailed    GenerationRunStatus = "failed"
+)
+
+// GenerationRun records one run of the background rota generator
+type GenerationRun struct {
+	ID             uint                `json:"id" gorm:"primaryKey"`
+	StartedAt      time.Time           `json:"started_at" gorm:"not null;index"`
+	FinishedAt     *time.Time          `json:"finished_at"`
+	Status         GenerationRunStatus `json:"status" gorm:"not null"`
+	FromWeek       time.Time           `json:"from_week" gorm:"not null"`
+	ToWeek         time.Time           `json:"to_week" gorm:"not null"`
+	WeeksGenerated int                 `json:"weeks_generated"`
+	WeeksSkipped   int                 `json:"weeks_skipped"`
+	Shortfalls     int                 `json:"shortfalls"` // Coverage shortfalls in the generated weeks
+	Error          string              `json:"error,omitempty"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Holiday is a public holiday. Rota generation applies the rule set's holiday staffing rules on it.
+type Holiday struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Date      time.Time `json:"date" gorm:"uniqueIndex;not null"`
+	Name      string    `json:"name" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// LeaveType defines the kind of absence
+type LeaveType string
+
+const (
+	LeaveAnnual   LeaveType = "annual"
+	LeaveSick     LeaveType = "sick"
+	LeaveTraining LeaveType = "training"
+	LeaveOther    LeaveType = "other"
+)
+
+// LeaveStatus defines the approval state of a leave request
+type LeaveStatus string
+
+const (
+	LeavePending  LeaveStatus = "pending"
+	LeaveApproved LeaveStatus = "approved"
+	LeaveRejected LeaveStatus = "rejected"
+)
+
+// Leave represents an officer's absence over an inclusive date range
+type Leave struct {
+	ID         uint        `json:"id" gorm:"primaryKey"`
+	OfficerID  uint        `json:"officer_id" gorm:"not null;index"`
+	Officer    Officer     `json:"officer" gorm:"foreignKey:OfficerID"`
+	StartDate  time.Time   `json:"start_date" gorm:"not null;index"`
+	EndDate    time.Time   `json:"end_date" gorm:"not null;index"`
+	Type       LeaveType   `json:"type" gorm:"not null"`
+	Status     LeaveStatus `json:"status" gorm:"not null;default:'pending'"`
+	Reason     string      `json:"reason"`
+	ReviewedBy *uint       `json:"reviewed_by"` // User who approved or rejected
+	ReviewedAt *time.Time  `json:"reviewed_at"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// Covers reports whether the leave includes the given date
+func (l Leave) Covers(date time.Time) bool {
+	return !date.Before(l.StartDate) && !date.After(l.EndDate)
+}
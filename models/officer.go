@@ -1,22 +1,99 @@
 package models
 
-import "time"
+import (
+	"time"
 
-// OfficerRole defines the role of an officer
+	"gorm.io/gorm"
+)
+
+// OfficerRole defines the duty role of an officer; gender is recorded separately
 type OfficerRole string
 
 const (
 	RoleSergeant OfficerRole = "sergeant"
-	RoleFemale   OfficerRole = "female"
 	RoleRegular  OfficerRole = "regular"
 )
 
+// OfficerGender is an officer's gender, used by shift templates and coverage rules
+type OfficerGender string
+
+const (
+	GenderFemale OfficerGender = "female"
+	GenderMale   OfficerGender = "male"
+)
+
+// OfficerStatus defines an officer's employment status
+type OfficerStatus string
+
+const (
+	OfficerActive    OfficerStatus = "active"
+	OfficerSuspended OfficerStatus = "suspended"
+	OfficerResigned  OfficerStatus = "resigned"
+)
+
+// DefaultContractedHours is the weekly hours an officer is contracted for unless set otherwise
+const DefaultContractedHours = 48
+
 // Officer represents a security officer
 type Officer struct {
-	ID        uint        `json:"id" gorm:"primaryKey"`
-	Name      string      `json:"name" gorm:"uniqueIndex;not null"`
-	Role      OfficerRole `json:"role" gorm:"not null;default:'regular'"`
-	Team      int         `json:"team" gorm:"not null"` // 1 or 2 for rotation teams
-	CreatedAt time.Time   `json:"created_at"`
-	UpdatedAt time.Time   `json:"updated_at"`
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	Name            string         `json:"name" gorm:"uniqueIndex:idx_officers_active_name,where:deleted_at IS NULL;not null"` // Unique among current officers
+	Role            OfficerRole    `json:"role" gorm:"not null;default:'regular'"`
+	Team            int            `json:"team" gorm:"not null"` // Rotation team, 1..team count of the active cycle
+	TeamSince       *time.Time     `json:"team_since"`           // Day the officer moved to their current team, nil if never moved
+	BadgeNo         *string        `json:"badge_no" gorm:"uniqueIndex:idx_officers_active_badge_no,where:deleted_at IS NULL"`
+	Rank            string         `json:"rank"`
+	ActingSergeant  bool           `json:"acting_sergeant" gorm:"not null;default:false"` // Can supervise shifts when no sergeant is on duty
+	Gender          OfficerGender  `json:"gender"`
+	Phone           string         `json:"phone"`
+	Email           string         `json:"email"`
+	Status          OfficerStatus  `json:"status" gorm:"not null;default:'active'"`
+	StartDate       *time.Time     `json:"start_date"`                                  // First day the officer can be rostered
+	EndDate         *time.Time     `json:"end_date"`                                    // Last day the officer can be rostered
+	ContractedHours float64        `json:"contracted_hours" gorm:"not null;default:48"` // Weekly hours; time worked above it is overtime
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Qualifications []Qualification `json:"qualifications,omitempty" gorm:"foreignKey:OfficerID"`
+}
+
+// Qualification is a certification held by an officer, e.g. first aid or a firearms licence
+type Qualification struct {
+	ID        uint       `json:"id" gorm:"primaryKey"`
+	OfficerID uint       `json:"officer_id" gorm:"not null;index"`
+	Officer   *Officer   `json:"officer,omitempty" gorm:"foreignKey:OfficerID"`
+	Name      string     `json:"name" gorm:"not null"`
+	IssuedOn  *time.Time `json:"issued_on"`
+	ExpiresOn *time.Time `json:"expires_on" gorm:"index"` // Nil for qualifications that do not expire
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// ValidOn reports whether the qualification has not expired by the given date
+func (q Qualification) ValidOn(date time.Time) bool {
+	return q.ExpiresOn == nil || !date.After(*q.ExpiresOn)
+}
+
+// CanSupervise reports whether the officer can supervise a shift, as a sergeant or acting sergeant
+func (o Officer) CanSupervise() bool {
+	return o.Role == RoleSergeant || o.ActingSergeant
+}
+
+// ActiveOn reports whether the officer can be rostered on the given date.
+// Suspended officers are never rostered; resigned officers work up to their end date.
+func (o Officer) ActiveOn(date time.Time) bool {
+	if o.StartDate != nil && date.Before(*o.StartDate) {
+		return false
+	}
+	if o.EndDate != nil && date.After(*o.EndDate) {
+		return false
+	}
+	switch o.Status {
+	case OfficerSuspended:
+		return false
+	case OfficerResigned:
+		return o.EndDate != nil
+	}
+	return true
 }
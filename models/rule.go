@@ -0,0 +1,120 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RuleSet groups the weekly rotation rules used by rota generation. Only one rule set is active at a time.
+type RuleSet struct {
+	ID          uint            `json:"id" gorm:"primaryKey"`
+	Name        string          `json:"name" gorm:"uniqueIndex;not null"`
+	Description string          `json:"description"`
+	Active      bool            `json:"active" gorm:"not null;default:false"`
+	Templates   []ShiftTemplate `json:"templates" gorm:"foreignKey:RuleSetID;constraint:OnDelete:CASCADE"`
+	Staffing    []StaffingRule  `json:"staffing" gorm:"foreignKey:RuleSetID;constraint:OnDelete:CASCADE"`
+	Coverage    []CoverageRule  `json:"coverage" gorm:"foreignKey:RuleSetID;constraint:OnDelete:CASCADE"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// ShiftTemplate pins officers to a fixed weekly pattern outside the team rotation.
+// It matches a specific officer, or the officers with a role and/or gender (optionally only the Nth by ID).
+type ShiftTemplate struct {
+	ID        uint          `json:"id" gorm:"primaryKey"`
+	RuleSetID uint          `json:"rule_set_id" gorm:"not null;index"`
+	Name      string        `json:"name"`
+	OfficerID *uint         `json:"officer_id"`
+	Role      OfficerRole   `json:"role"`
+	Gender    OfficerGender `json:"gender"`
+	Position  *int          `json:"position"` // 0-based index among officers matching Role and Gender, ordered by ID
+	ShiftType ShiftType     `json:"shift_type" gorm:"not null"`
+	DaysOff   string        `json:"days_off"` // Comma-separated weekdays, e.g. "sat" or "sun,sat"
+	SortOrder int           `json:"sort_order"`
+}
+
+// StaffingRule controls how many officers of the team on a shift work on a given weekday,
+// or on public holidays
+type StaffingRule struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	RuleSetID uint      `json:"rule_set_id" gorm:"not null;index"`
+	Weekday   int       `json:"weekday"` // 0 = Sunday ... 6 = Saturday
+	Holiday   bool      `json:"holiday"` // Applies on public holidays in place of the weekday rule; Weekday is ignored
+	ShiftType ShiftType `json:"shift_type" gorm:"not null"`
+	MaxOnDuty int       `json:"max_on_duty"` // 0 = whole team; otherwise the rest of the team is off
+	OffCount  int       `json:"off_count"`   // Officers rostered off, rotating through the team
+}
+
+// CoverageRule sets the minimum staffing and mix of officers on duty on a shift. The generator meets
+// the female minimum from whichever officers qualify and reports any rule it cannot meet.
+type CoverageRule struct {
+	ID                uint      `json:"id" gorm:"primaryKey"`
+	RuleSetID         uint      `json:"rule_set_id" gorm:"not null;index"`
+	Weekday           *int      `json:"weekday"` // 0 = Sunday ... 6 = Saturday; nil = every day
+	ShiftType         ShiftType `json:"shift_type" gorm:"not null"`
+	MinOnDuty         int       `json:"min_on_duty"`        // Officers on duty
+	RequireSergeant   bool      `json:"require_sergeant"`   // At least one sergeant on duty
+	RequireSupervisor bool      `json:"require_supervisor"` // A sergeant or acting sergeant on duty
+	MinFemale         int       `json:"min_female"`         // Female officers on duty, of any role
+}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// ParseWeekdays parses a comma-separated list of weekday abbreviations (sun..sat)
+func ParseWeekdays(s string) ([]time.Weekday, error) {
+	var days []time.Weekday
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		found := false
+		for i, name := range weekdayNames {
+			if strings.HasPrefix(part, name) {
+				days = append(days, time.Weekday(i))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("invalid weekday: %s", part)
+		}
+	}
+	return days, nil
+}
+
+// IsDayOff reports whether the template rests on the given weekday
+func (t ShiftTemplate) IsDayOff(weekday time.Weekday) bool {
+	days, _ := ParseWeekdays(t.DaysOff)
+	for _, d := range days {
+		if d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRuleSet returns the rules the rota has always used
+func DefaultRuleSet() RuleSet {
+	first := 0
+	return RuleSet{
+		Name:        "Default",
+		Description: "Sergeant on a fixed day shift; a sergeant or acting sergeant supervising every shift; at least one female officer on every day shift; Sunday reduced day shift; two night officers off Mon-Thu",
+		Active:      true,
+		Templates: []ShiftTemplate{
+			{Name: "Sergeant", Role: RoleSergeant, Position: &first, ShiftType: ShiftDay, DaysOff: "sat", SortOrder: 1},
+		},
+		Staffing: []StaffingRule{
+			{Weekday: int(time.Sunday), ShiftType: ShiftDay, MaxOnDuty: 2},
+			{Weekday: int(time.Monday), ShiftType: ShiftNight, OffCount: 2},
+			{Weekday: int(time.Tuesday), ShiftType: ShiftNight, OffCount: 2},
+			{Weekday: int(time.Wednesday), ShiftType: ShiftNight, OffCount: 2},
+			{Weekday: int(time.Thursday), ShiftType: ShiftNight, OffCount: 2},
+		},
+		Coverage: []CoverageRule{
+			{ShiftType: ShiftDay, MinOnDuty: 1, RequireSupervisor: true, MinFemale: 1},
+			{ShiftType: ShiftNight, MinOnDuty: 1, RequireSupervisor: true},
+		},
+	}
+}
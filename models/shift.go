@@ -2,9 +2,10 @@ package models
 
 import "time"
 
-// ShiftType defines day or night shift
+// ShiftType is the code of a ShiftDefinition, e.g. day or night
 type ShiftType string
 
+// Codes of the default shift definitions
 const (
 	ShiftDay   ShiftType = "day"
 	ShiftNight ShiftType = "night"
@@ -16,25 +17,34 @@ type DutyStatus string
 const (
 	StatusOnDuty  DutyStatus = "on_duty"
 	StatusOffDuty DutyStatus = "off_duty"
+	StatusOnLeave DutyStatus = "on_leave"
 )
 
 // Shift represents a duty assignment for an officer on a specific date
 type Shift struct {
 	ID        uint       `json:"id" gorm:"primaryKey"`
-	OfficerID uint       `json:"officer_id" gorm:"not null;index"`
+	OfficerID uint       `json:"officer_id" gorm:"not null;index;uniqueIndex:idx_shifts_officer_date_type"`
 	Officer   Officer    `json:"officer" gorm:"foreignKey:OfficerID"`
-	Date      time.Time  `json:"date" gorm:"not null;index"`
-	ShiftType ShiftType  `json:"shift_type" gorm:"not null"`
+	Date      time.Time  `json:"date" gorm:"not null;index;uniqueIndex:idx_shifts_officer_date_type"`
+	ShiftType ShiftType  `json:"shift_type" gorm:"not null;uniqueIndex:idx_shifts_officer_date_type"`
 	Status    DutyStatus `json:"status" gorm:"not null"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	Manual    bool       `json:"manual" gorm:"not null;default:false"` // Edited by hand; kept when the week is regenerated
+	Relief    bool       `json:"relief" gorm:"not null;default:false"` // Acting sergeant supervising in the sergeant's absence
+	// Status the officer was rostered with before approved leave marked the shift on_leave; restored if the
+	// leave is withdrawn
+	StatusBeforeLeave DutyStatus `json:"status_before_leave,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
 }
 
 // WeekRotation tracks which team is on which shift for a given week
 type WeekRotation struct {
-	ID           uint      `json:"id" gorm:"primaryKey"`
-	WeekStart    time.Time `json:"week_start" gorm:"uniqueIndex;not null"` // Sunday of the week
-	DayShiftTeam int       `json:"day_shift_team" gorm:"not null"`         // Team 1 or 2
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	WeekStart      time.Time `json:"week_start" gorm:"uniqueIndex;not null"` // Sunday of the week
+	DayShiftTeam   int       `json:"day_shift_team" gorm:"not null"`         // Team on day shift
+	NightShiftTeam int       `json:"night_shift_team"`                       // Team on night shift, 0 if none
+	CycleID        *uint     `json:"cycle_id"`                               // Rotation cycle the week was generated from
+	CycleWeek      int       `json:"cycle_week"`                             // 0-based position in that cycle
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
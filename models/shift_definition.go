@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// ShiftDefinition describes a shift a site works. Its Code is the ShiftType stored on shifts, rules
+// and rotation cycle steps.
+type ShiftDefinition struct {
+	ID              uint      `json:"id" gorm:"primaryKey"`
+	Code            ShiftType `json:"code" gorm:"uniqueIndex;not null"`
+	Label           string    `json:"label" gorm:"not null"`
+	StartTime       string    `json:"start_time" gorm:"not null"` // HH:MM
+	EndTime         string    `json:"end_time" gorm:"not null"`   // HH:MM
+	CrossesMidnight bool      `json:"crosses_midnight"`           // Ends on the day after it starts
+	Colour          string    `json:"colour"`                     // #RRGGBB, used by rota views and exports
+	SortOrder       int       `json:"sort_order"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Hours returns the length of the shift in hours
+func (d ShiftDefinition) Hours() float64 {
+	start, err := time.Parse("15:04", d.StartTime)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse("15:04", d.EndTime)
+	if err != nil {
+		return 0
+	}
+	if d.CrossesMidnight {
+		end = end.Add(24 * time.Hour)
+	}
+	return end.Sub(start).Hours()
+}
+
+// DefaultShiftDefinitions returns the 12-hour day and night shifts the rota has always used
+func DefaultShiftDefinitions() []ShiftDefinition {
+	return []ShiftDefinition{
+		{Code: ShiftDay, Label: "Day Shift", StartTime: "06:00", EndTime: "18:00", Colour: "#FFF2CC", SortOrder: 1},
+		{Code: ShiftNight, Label: "Night Shift", StartTime: "18:00", EndTime: "06:00", CrossesMidnight: true, Colour: "#D9E1F2", SortOrder: 2},
+	}
+}
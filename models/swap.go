@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// SwapStatus defines the state of a shift swap request
+type SwapStatus string
+
+const (
+	SwapPending   SwapStatus = "pending"   // waiting for the colleague to accept
+	SwapAccepted  SwapStatus = "accepted"  // waiting for supervisor approval
+	SwapApproved  SwapStatus = "approved"  // shifts have been exchanged
+	SwapDeclined  SwapStatus = "declined"  // colleague declined
+	SwapRejected  SwapStatus = "rejected"  // supervisor rejected
+	SwapCancelled SwapStatus = "cancelled" // requester withdrew
+)
+
+// ShiftSwap represents an officer's request to trade one of their shifts for a colleague's
+type ShiftSwap struct {
+	ID               uint       `json:"id" gorm:"primaryKey"`
+	RequesterID      uint       `json:"requester_id" gorm:"not null;index"`
+	Requester        Officer    `json:"requester" gorm:"foreignKey:RequesterID"`
+	RequesterShiftID uint       `json:"requester_shift_id" gorm:"not null"`
+	RequesterShift   Shift      `json:"requester_shift" gorm:"foreignKey:RequesterShiftID"`
+	TargetOfficerID  uint       `json:"target_officer_id" gorm:"not null;index"`
+	TargetOfficer    Officer    `json:"target_officer" gorm:"foreignKey:TargetOfficerID"`
+	TargetShiftID    uint       `json:"target_shift_id" gorm:"not null"`
+	TargetShift      Shift      `json:"target_shift" gorm:"foreignKey:TargetShiftID"`
+	Status           SwapStatus `json:"status" gorm:"not null;default:'pending';index"`
+	Reason           string     `json:"reason"`
+	ReviewedBy       *uint      `json:"reviewed_by"` // Supervisor who approved or rejected
+	ReviewedAt       *time.Time `json:"reviewed_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
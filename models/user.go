@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// User roles used for route authorization
+const (
+	UserRoleAdmin      = "Admin"
+	UserRoleSupervisor = "Supervisor"
+	UserRoleViewer     = "Viewer"
+	UserRoleOfficer    = "Officer"
+)
+
+// User represents an account that can log in to the API
+type User struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	Username     string    `json:"username" gorm:"uniqueIndex;not null"`
+	PasswordHash string    `json:"-" gorm:"not null"`
+	FullName     string    `json:"fullName"`
+	Email        string    `json:"email"`
+	Role         string    `json:"role" gorm:"not null;default:'Viewer'"`
+	OfficerID    *uint     `json:"officer_id" gorm:"index"` // Officer this account belongs to, for Officer-role users
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
@@ -12,16 +12,16 @@ echo
 echo "Creating Female Officers (2)..."
 curl -s -X POST "$BASE_URL/officers" \
   -H "Content-Type: application/json" \
-  -d '{"name":"Faides","role":"female","team":1}'
+  -d '{"name":"Faides","role":"regular","gender":"female","team":1}'
 echo
 curl -s -X POST "$BASE_URL/officers" \
   -H "Content-Type: application/json" \
-  -d '{"name":"Abigail","role":"female","team":1}'
+  -d '{"name":"Abigail","role":"regular","gender":"female","team":1}'
 echo
 
 echo "Creating Team A Regular Officers (8)..."
 curl -s -X POST "$BASE_URL/officers" -H "Content-Type: application/json" \
-  -d '{"name":"Alexander","role":"regular","team":1}'
+  -d '{"name":"Alexander","role":"regular","acting_sergeant":true,"team":1}'
 echo
 curl -s -X POST "$BASE_URL/officers" -H "Content-Type: application/json" \
   -d '{"name":"Levy","role":"regular","team":1}'
@@ -47,7 +47,7 @@ echo
 
 echo "Creating Team B Regular Officers (8)..."
 curl -s -X POST "$BASE_URL/officers" -H "Content-Type: application/json" \
-  -d '{"name":"Moses","role":"regular","team":2}'
+  -d '{"name":"Moses","role":"regular","acting_sergeant":true,"team":2}'
 echo
 curl -s -X POST "$BASE_URL/officers" -H "Content-Type: application/json" \
   -d '{"name":"Michael","role":"regular","team":2}'